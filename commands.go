@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/yaml"
+)
+
+// The output formats of the subcommands
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputYAML = "yaml"
+)
+
+// podInfo is a pod shown by the pods command
+type podInfo struct {
+	Name      string          `json:"name"`
+	Status    types.PodStatus `json:"status"`
+	Restarts  int32           `json:"restarts"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// containerInfo is a container shown by the containers command
+type containerInfo struct {
+	Name     string `json:"name"`
+	Init     bool   `json:"init"`
+	State    string `json:"state"`
+	Reason   string `json:"reason,omitempty"`
+	Restarts int32  `json:"restarts"`
+}
+
+// logInfo is a line shown by the dump command
+type logInfo struct {
+	Container string    `json:"container"`
+	Time      time.Time `json:"time"`
+	Text      string    `json:"text"`
+}
+
+// dumpOptions is options to dump logs of the pod
+type dumpOptions struct {
+	namespace  string
+	pod        string
+	container  string
+	output     string
+	timestamps bool
+}
+
+func newPodsCommand(p *params) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{}
+	cmd.Use = "pods"
+	cmd.Short = "Print pods with their status"
+	cmd.Args = cobra.NoArgs
+	addOutputFlag(cmd, &output)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(output); err != nil {
+			return err
+		}
+		client, config, err := p.loadConfig()
+		if err != nil {
+			return err
+		}
+		return runPods(client, cmd.OutOrStdout(), config.Namespace, config.Selector, output)
+	}
+	return cmd
+}
+
+func newContainersCommand(p *params) *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{}
+	cmd.Use = "containers POD"
+	cmd.Short = "Print containers of the pod with their state"
+	cmd.Args = cobra.ExactArgs(1)
+	addOutputFlag(cmd, &output)
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(output); err != nil {
+			return err
+		}
+		client, config, err := p.loadConfig()
+		if err != nil {
+			return err
+		}
+		return runContainers(client, cmd.OutOrStdout(), config.Namespace, args[0], output)
+	}
+	return cmd
+}
+
+func newDumpCommand(p *params) *cobra.Command {
+	var opts dumpOptions
+
+	cmd := &cobra.Command{}
+	cmd.Use = "dump POD [CONTAINER]"
+	cmd.Short = "Print whole logs of the pod without following"
+	cmd.Long = `Print whole logs of the pod without following.  Logs of all containers
+in the pod are printed with the container name if CONTAINER is omitted.`
+	cmd.Args = cobra.RangeArgs(1, 2)
+	addOutputFlag(cmd, &opts.output)
+	cmd.Flags().BoolVarP(&opts.timestamps, "timestamps", "", opts.timestamps, "Prefix each line with the timestamp in text output")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if err := validateOutput(opts.output); err != nil {
+			return err
+		}
+		client, config, err := p.loadConfig()
+		if err != nil {
+			return err
+		}
+		opts.namespace = config.Namespace
+		opts.pod = args[0]
+		if len(args) > 1 {
+			opts.container = args[1]
+		}
+		return runDump(context.Background(), client, cmd.OutOrStdout(), opts)
+	}
+	return cmd
+}
+
+func addOutputFlag(cmd *cobra.Command, output *string) {
+	*output = outputText
+	cmd.Flags().StringVarP(output, "output", "o", *output, "Output format, one of text, json or yaml")
+}
+
+func validateOutput(output string) error {
+	switch output {
+	case outputText, outputJSON, outputYAML:
+		return nil
+	}
+	return errors.Errorf("unknown output format: %s", output)
+}
+
+// runPods prints pods in namespace selected by the selector to w
+func runPods(client *k8s.Client, w io.Writer, namespace, selector, output string) error {
+	list, err := client.ListPods(namespace, k8s.PodOptions{LabelSelector: selector})
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+
+	pods := make([]podInfo, 0, len(list.Items))
+	for i := range list.Items {
+		pod := &list.Items[i]
+		var restarts int32
+		for _, s := range pod.Status.ContainerStatuses {
+			restarts += s.RestartCount
+		}
+		pods = append(pods, podInfo{
+			Name:      pod.Name,
+			Status:    types.GetPodStatus(pod),
+			Restarts:  restarts,
+			CreatedAt: pod.CreationTimestamp.Time,
+		})
+	}
+
+	return printOutput(w, output, pods, func(tw io.Writer) {
+		now := time.Now()
+		fmt.Fprintln(tw, "NAME\tSTATUS\tRESTARTS\tAGE")
+		for _, pod := range pods {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", pod.Name, pod.Status, pod.Restarts, formatAge(pod.CreatedAt, now))
+		}
+	})
+}
+
+// runContainers prints containers of the pod in namespace to w
+func runContainers(client *k8s.Client, w io.Writer, namespace, name, output string) error {
+	pod, err := client.GetPod(namespace, name)
+	if err != nil {
+		return errors.Wrap(err, "failed to get pod")
+	}
+
+	containers := make([]containerInfo, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers))
+	for _, c := range pod.Spec.InitContainers {
+		containers = append(containers, newContainerInfo(c.Name, true, pod.Status.InitContainerStatuses))
+	}
+	for _, c := range pod.Spec.Containers {
+		containers = append(containers, newContainerInfo(c.Name, false, pod.Status.ContainerStatuses))
+	}
+
+	return printOutput(w, output, containers, func(tw io.Writer) {
+		fmt.Fprintln(tw, "NAME\tINIT\tSTATE\tREASON\tRESTARTS")
+		for _, c := range containers {
+			fmt.Fprintf(tw, "%s\t%t\t%s\t%s\t%d\n", c.Name, c.Init, c.State, c.Reason, c.Restarts)
+		}
+	})
+}
+
+func newContainerInfo(name string, init bool, statuses []corev1.ContainerStatus) containerInfo {
+	info := containerInfo{Name: name, Init: init, State: "Waiting"}
+	for _, s := range statuses {
+		if s.Name != name {
+			continue
+		}
+		info.Restarts = s.RestartCount
+		switch {
+		case s.State.Running != nil:
+			info.State = "Running"
+		case s.State.Terminated != nil:
+			info.State = "Terminated"
+			info.Reason = s.State.Terminated.Reason
+		case s.State.Waiting != nil:
+			info.Reason = s.State.Waiting.Reason
+		}
+	}
+	return info
+}
+
+// runDump prints whole logs of the containers in the pod selected by opts to
+// w.  The lines are prefixed by the container name in text output if logs of
+// all containers are printed.
+func runDump(ctx context.Context, client *k8s.Client, w io.Writer, opts dumpOptions) error {
+	containers := []string{opts.container}
+	if len(opts.container) == 0 {
+		pod, err := client.GetPod(opts.namespace, opts.pod)
+		if err != nil {
+			return errors.Wrap(err, "failed to get pod")
+		}
+		containers = k8s.ContainerNames(pod)
+	}
+
+	var logs []logInfo
+	for _, container := range containers {
+		ch, err := client.ReadLogs(ctx, opts.namespace, opts.pod, container, k8s.LogOptions{Timestamps: true})
+		if err != nil {
+			return errors.Wrapf(err, "failed to read logs of %s", container)
+		}
+		for line := range ch {
+			if opts.output == outputText {
+				var prefix string
+				if len(opts.container) == 0 {
+					prefix = containerPrefix(opts.pod, container)
+				}
+				if _, err := io.WriteString(w, formatLine(line.Text, line.Time, opts.timestamps, prefix)); err != nil {
+					return err
+				}
+				continue
+			}
+			logs = append(logs, logInfo{Container: container, Time: line.Time, Text: line.Text})
+		}
+	}
+	if opts.output == outputText {
+		return nil
+	}
+	if logs == nil {
+		logs = []logInfo{}
+	}
+	return printOutput(w, opts.output, logs, nil)
+}
+
+// printOutput prints v to w in JSON or YAML, or calls text with a tabwriter
+// in text output
+func printOutput(w io.Writer, output string, v interface{}, text func(w io.Writer)) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case outputText:
+		tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+		text(tw)
+		return tw.Flush()
+	}
+	return errors.Errorf("unknown output format: %s", output)
+}
+
+// formatAge returns the elapsed time from t, such as "5m"
+func formatAge(t, now time.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return strings.TrimSpace(duration.HumanDuration(now.Sub(t)))
+}
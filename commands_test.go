@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func testPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nginx",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "nginx"},
+		},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "app"}, {Name: "sidecar"}},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "init",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				Ready:        true,
+				RestartCount: 2,
+				State:        corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}, {
+				Name:  "sidecar",
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+			}},
+		},
+	}
+}
+
+func TestRunPods(t *testing.T) {
+	other := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "redis", Namespace: "default", Labels: map[string]string{"app": "redis"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	client := k8s.NewClientForClientset(fake.NewSimpleClientset(testPod(), other))
+
+	cases := []struct {
+		selector string
+		output   string
+		expected string
+	}{
+		{"", "text", `NAME    STATUS         RESTARTS   AGE
+nginx   Initializing   2          <unknown>
+redis   Pending        0          <unknown>
+`},
+		{"app=nginx", "json", `[
+  {
+    "name": "nginx",
+    "status": "Initializing",
+    "restarts": 2,
+    "createdAt": "0001-01-01T00:00:00Z"
+  }
+]
+`},
+		{"app=redis", "yaml", `- createdAt: "0001-01-01T00:00:00Z"
+  name: redis
+  restarts: 0
+  status: Pending
+`},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		err := runPods(client, &buf, "default", c.selector, c.output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.expected {
+			t.Errorf("%s: expected %q, actual %q", c.output, c.expected, buf.String())
+		}
+	}
+}
+
+func TestRunContainers(t *testing.T) {
+	client := k8s.NewClientForClientset(fake.NewSimpleClientset(testPod()))
+
+	var buf bytes.Buffer
+	err := runContainers(client, &buf, "default", "nginx", "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `NAME      INIT    STATE        REASON             RESTARTS
+init      true    Terminated   Completed          0
+app       false   Running                         2
+sidecar   false   Waiting      CrashLoopBackOff   0
+`
+	if buf.String() != expected {
+		t.Errorf("expected %q, actual %q", expected, buf.String())
+	}
+
+	err = runContainers(client, &buf, "default", "missing", "text")
+	if err == nil {
+		t.Error("expected error for missing pod")
+	}
+}
+
+func TestRunDump(t *testing.T) {
+	pod := testPod()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/log") {
+			container := r.URL.Query().Get("container")
+			fmt.Fprintf(w, "2019-07-01T12:00:00Z hello from %s\n", container)
+			fmt.Fprintf(w, "2019-07-01T12:00:01Z bye from %s\n", container)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"kind":"Pod","apiVersion":"v1","metadata":{"name":%q,"namespace":"default"},"spec":{"initContainers":[{"name":"init"}],"containers":[{"name":"app"}]}}`, pod.Name)
+	}))
+	defer server.Close()
+
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := k8s.NewClientForClientset(clientset)
+
+	cases := []struct {
+		opts     dumpOptions
+		expected string
+	}{
+		{
+			dumpOptions{pod: "nginx", container: "app", output: "text"},
+			"hello from app\nbye from app\n",
+		},
+		{
+			dumpOptions{pod: "nginx", container: "app", output: "text", timestamps: true},
+			"2019-07-01T12:00:00Z hello from app\n2019-07-01T12:00:01Z bye from app\n",
+		},
+		{
+			dumpOptions{pod: "nginx", output: "text"},
+			"[nginx/init] hello from init\n[nginx/init] bye from init\n[nginx/app] hello from app\n[nginx/app] bye from app\n",
+		},
+		{
+			dumpOptions{pod: "nginx", container: "app", output: "yaml"},
+			`- container: app
+  text: hello from app
+  time: "2019-07-01T12:00:00Z"
+- container: app
+  text: bye from app
+  time: "2019-07-01T12:00:01Z"
+`,
+		},
+	}
+	for _, c := range cases {
+		c.opts.namespace = "default"
+		var buf bytes.Buffer
+		err := runDump(context.Background(), client, &buf, c.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.expected {
+			t.Errorf("%+v: expected %q, actual %q", c.opts, c.expected, buf.String())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ueokande/logbook/pkg/config"
+	"github.com/ueokande/logbook/pkg/level"
+	"github.com/ueokande/logbook/pkg/ui"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// loadConfigFile loads the config file and applies it to the parameters of
+// cmd.  The missing file is ignored unless the path is given by the flag.
+func (p *params) loadConfigFile(root, cmd *cobra.Command) error {
+	c, err := config.Load(p.configPath)
+	if os.IsNotExist(err) && !cmd.Flags().Changed("config") {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if err := p.applyConfig(root, cmd, c); err != nil {
+		return errors.Wrapf(err, "invalid config file %s", p.configPath)
+	}
+	return nil
+}
+
+func (p *params) applyConfig(root, cmd *cobra.Command, c *config.Config) error {
+	if err := applyDefaults(root, cmd.Flags(), c.Defaults); err != nil {
+		return errors.Wrap(err, "defaults")
+	}
+
+	keymap, err := ui.ParseKeybindings(c.Keybindings)
+	if err != nil {
+		return errors.Wrap(err, "keybindings")
+	}
+	if err := ui.SetColors(c.Colors); err != nil {
+		return errors.Wrap(err, "colors")
+	}
+	p.keymap = keymap
+
+	// the values of the pager are validated on load
+	p.wrap = c.Pager.Wrap
+	p.lineNumbers = c.Pager.LineNumbers
+	p.follow = c.Pager.Follow
+	if len(c.Pager.Timestamps) > 0 {
+		p.timestamps, _ = widgets.ParseTimestampFormat(c.Pager.Timestamps)
+	}
+	p.minLevel = level.Parse(c.Pager.MinLevel)
+	return nil
+}
+
+// applyDefaults sets the default values to the flags not given in the
+// command-line.  The names of the flags are looked up in all commands, and
+// the flags not in flags are ignored.
+func applyDefaults(root *cobra.Command, flags *pflag.FlagSet, defaults map[string]interface{}) error {
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	known := make(map[string]bool)
+	collectFlags(root, known)
+	for _, name := range names {
+		if !known[name] {
+			return errors.Errorf("unknown flag %q", name)
+		}
+		f := flags.Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		// Set() of the flag set is not used to keep it unchanged
+		if err := f.Value.Set(flagValue(defaults[name])); err != nil {
+			return errors.Wrapf(err, "flag %s", name)
+		}
+	}
+	return nil
+}
+
+func collectFlags(cmd *cobra.Command, names map[string]bool) {
+	visit := func(f *pflag.Flag) {
+		names[f.Name] = true
+	}
+	cmd.PersistentFlags().VisitAll(visit)
+	cmd.Flags().VisitAll(visit)
+	for _, c := range cmd.Commands() {
+		collectFlags(c, names)
+	}
+}
+
+// flagValue returns the value in the config file as the value of the flag.
+// The lists are joined with commas.
+func flagValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		values := make([]string, len(v))
+		for i, e := range v {
+			values[i] = flagValue(e)
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(v)
+}
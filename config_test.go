@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	var namespace, record string
+	var json bool
+	var fields []string
+	var maxLines int
+
+	root := &cobra.Command{Use: "logbook"}
+	root.PersistentFlags().StringVarP(&namespace, "namespace", "n", "", "")
+	root.Flags().StringVarP(&record, "record", "", "", "")
+	sub := &cobra.Command{Use: "view"}
+	sub.Flags().BoolVarP(&json, "json", "", false, "")
+	sub.Flags().StringSliceVarP(&fields, "json-time-fields", "", []string{"ts"}, "")
+	sub.Flags().IntVarP(&maxLines, "max-lines", "", 0, "")
+	root.AddCommand(sub)
+	if err := sub.ParseFlags([]string{"--json=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := applyDefaults(root, sub.Flags(), map[string]interface{}{
+		"json":             true,
+		"json-time-fields": []interface{}{"time", "@timestamp"},
+		"max-lines":        float64(10000),
+		"record":           "/tmp/logs",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the flags given in the command-line take precedence
+	if json {
+		t.Error("the flag in the command-line is overwritten")
+	}
+	if !reflect.DeepEqual(fields, []string{"time", "@timestamp"}) || maxLines != 10000 {
+		t.Errorf("unexpected values: %q, %d", fields, maxLines)
+	}
+	// the flags of other commands are ignored
+	if len(record) > 0 {
+		t.Errorf("the flag of other command is set: %q", record)
+	}
+	if sub.Flags().Changed("max-lines") {
+		t.Error("the default is marked as changed")
+	}
+
+	err = applyDefaults(root, sub.Flags(), map[string]interface{}{"namspace": "default"})
+	if err == nil || !strings.Contains(err.Error(), `unknown flag "namspace"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	err = applyDefaults(root, sub.Flags(), map[string]interface{}{"max-lines": "many"})
+	if err == nil || !strings.Contains(err.Error(), "flag max-lines") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/ueokande/logbook/pkg/docker"
+	"github.com/ueokande/logbook/pkg/source"
+)
+
+func newDockerCommand(p *params) *cobra.Command {
+	var host string
+
+	cmd := &cobra.Command{}
+	cmd.Use = "docker"
+	cmd.Short = "View logs of local containers in Docker"
+	cmd.Long = `View logs of local containers in Docker.  Containers created by docker
+compose are grouped by the project, and its services are shown in the tabs.`
+	cmd.Args = cobra.NoArgs
+	cmd.Flags().StringVarP(&host, "host", "H", host, "Address of Docker Engine, such as \"unix:///var/run/docker.sock\".  Default to DOCKER_HOST")
+	p.addUIFlags(cmd.Flags())
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		client, err := docker.NewClient(host)
+		if err != nil {
+			return err
+		}
+		config := p.appConfig()
+		config.Cluster = "docker"
+		config.Namespace = "local"
+
+		return NewApp(source.NewDocker(client), config).Run(context.Background())
+	}
+	return cmd
+}
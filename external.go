@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/ansi"
+	"github.com/ueokande/logbook/pkg/clipboard"
+	"github.com/ueokande/logbook/pkg/ui"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// Suspend finalizes the screen and runs f with the terminal.  The screen is
+// resumed after f returns.  It must be called in the event loop.
+func (app *App) Suspend(f func() error) error {
+	if app.screen == nil {
+		return errors.New("screen is not initialized")
+	}
+	app.screen.Fini()
+
+	// Interrupts by Ctrl-C are sent to the child process, not to logbook
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt)
+	err := f()
+	signal.Stop(sig)
+
+	if ierr := app.screen.Init(); ierr != nil {
+		app.Quit()
+		return errors.Wrap(ierr, "failed to resume screen")
+	}
+	app.ui.Resize()
+	app.screen.Sync()
+	return err
+}
+
+// OnPipeRequested handles events on piping lines to the command is required
+// by UI.  The command is run by sh with the terminal.
+func (app *App) OnPipeRequested(command string, lines []widgets.Line) {
+	err := app.Suspend(func() error {
+		err := app.runWithLines(exec.Command("sh", "-c", command), lines)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		app.execCommand(exec.Command("sh", "-c", pressEnterScript))
+		return err
+	})
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+	}
+}
+
+// OnPagerRequested handles events on opening lines in $PAGER is required by
+// UI.
+func (app *App) OnPagerRequested(lines []widgets.Line) {
+	pager := os.Getenv("PAGER")
+	if len(pager) == 0 {
+		pager = "less"
+	}
+	err := app.Suspend(func() error {
+		return app.runWithLines(exec.Command("sh", "-c", pager), lines)
+	})
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+	}
+}
+
+// OnEditorRequested handles events on opening lines in $EDITOR is required by
+// UI.  The lines are written to a temporary file, and it is removed after the
+// editor exits.
+func (app *App) OnEditorRequested(lines []widgets.Line) {
+	editor := os.Getenv("VISUAL")
+	if len(editor) == 0 {
+		editor = os.Getenv("EDITOR")
+	}
+	if len(editor) == 0 {
+		editor = "vi"
+	}
+
+	f, err := ioutil.TempFile("", "logbook-*.log")
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+		return
+	}
+	defer os.Remove(f.Name())
+	f.Close()
+	if _, err := saveLines(f.Name(), lines, ui.SaveOptions{}, ""); err != nil {
+		app.ui.ShowMessage(err.Error())
+		return
+	}
+
+	err = app.Suspend(func() error {
+		// pass the path as an argument because the editor can contain flags
+		cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", f.Name())
+		return errors.Wrap(app.execCommand(cmd), "failed to run editor")
+	})
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+	}
+}
+
+// OnCopyRequested handles events on copying lines to the clipboard is
+// required by UI.  ANSI escape sequences in the lines are stripped.
+func (app *App) OnCopyRequested(lines []widgets.Line) {
+	var buf bytes.Buffer
+	for _, line := range lines {
+		buf.WriteString(ansi.Strip(line.Text))
+		buf.WriteByte('\n')
+	}
+
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+		return
+	}
+	defer tty.Close()
+
+	err = clipboard.New(tty, app.clipboardCommand).Copy(buf.String())
+	if err != nil {
+		app.ui.ShowMessage(err.Error())
+		return
+	}
+	app.ui.ShowMessage(fmt.Sprintf("%d lines copied", len(lines)))
+}
+
+// pressEnterScript is the shell script to wait for Enter before returning to
+// logbook, to let the user read the output of the command.
+const pressEnterScript = `printf '\nPress Enter to return to logbook' >&2; read _`
+
+// runWithLines runs the cmd with the lines as stdin, and the terminal as
+// stdout and stderr.
+func (app *App) runWithLines(cmd *exec.Cmd, lines []widgets.Line) error {
+	r, w := io.Pipe()
+	cmd.Stdin = r
+	go func() {
+		// the command may exit without reading all lines
+		_, err := writeLines(w, lines, ui.SaveOptions{}, "")
+		w.CloseWithError(err)
+	}()
+
+	err := app.execCommand(cmd)
+	r.Close()
+	return errors.Wrap(err, "command failed")
+}
+
+// execTerminal runs the cmd with the terminal as stdout and stderr.  The
+// terminal is also stdin unless the cmd has its stdin.
+func execTerminal(cmd *exec.Cmd) error {
+	if cmd.Stdin == nil {
+		tty, close := openTerminal()
+		defer close()
+		cmd.Stdin = tty
+	}
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// openTerminal opens the terminal to read input from the user, because stdin
+// can be logs piped to "logbook view -".  It falls back to stdin if the
+// terminal is not available.  The returned function closes the terminal.
+func openTerminal() (io.Reader, func()) {
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return os.Stdin, func() {}
+	}
+	return tty, func() { tty.Close() }
+}
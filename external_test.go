@@ -0,0 +1,115 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/source"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// execRecorder records the commands run by the app and their stdin instead
+// of running them with the terminal
+type execRecorder struct {
+	args   [][]string
+	inputs []string
+}
+
+func (r *execRecorder) exec(cmd *exec.Cmd) error {
+	var input []byte
+	if cmd.Stdin != nil {
+		var err error
+		input, err = ioutil.ReadAll(cmd.Stdin)
+		if err != nil {
+			return err
+		}
+	}
+	r.args = append(r.args, cmd.Args)
+	r.inputs = append(r.inputs, string(input))
+	return nil
+}
+
+func startExternalTest(t *testing.T) (*App, *execRecorder) {
+	screen := tcell.NewSimulationScreen("")
+	screen.SetSize(80, 24)
+	app := NewApp(source.NewMemory(), &AppConfig{})
+	r := &execRecorder{}
+	app.execCommand = r.exec
+	app.start(screen)
+	return app, r
+}
+
+func stopExternalTest(t *testing.T, app *App) {
+	app.Quit()
+	if err := app.wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var externalTestLines = []widgets.Line{
+	{Text: "\x1b[31mERROR\x1b[0m failed"},
+	{Text: "retrying"},
+}
+
+func TestAppOnPipeRequested(t *testing.T) {
+	app, r := startExternalTest(t)
+	defer stopExternalTest(t, app)
+
+	waitForApp(t, app, func() bool {
+		app.OnPipeRequested("grep -c ERROR", externalTestLines)
+		return true
+	})
+
+	expectedArgs := [][]string{
+		{"sh", "-c", "grep -c ERROR"},
+		{"sh", "-c", pressEnterScript},
+	}
+	if !reflect.DeepEqual(r.args, expectedArgs) {
+		t.Errorf("unexpected commands: %q", r.args)
+	}
+	expectedInputs := []string{"\x1b[31mERROR\x1b[0m failed\nretrying\n", ""}
+	if !reflect.DeepEqual(r.inputs, expectedInputs) {
+		t.Errorf("unexpected inputs: %q", r.inputs)
+	}
+}
+
+func TestAppOnEditorRequested(t *testing.T) {
+	app, r := startExternalTest(t)
+	defer stopExternalTest(t, app)
+
+	var content string
+	app.execCommand = func(cmd *exec.Cmd) error {
+		// the file is removed after the editor exits
+		b, err := ioutil.ReadFile(cmd.Args[len(cmd.Args)-1])
+		content = string(b)
+		if err != nil {
+			return err
+		}
+		return r.exec(cmd)
+	}
+	os.Setenv("VISUAL", "vim -R")
+	defer os.Unsetenv("VISUAL")
+
+	waitForApp(t, app, func() bool {
+		app.OnEditorRequested(externalTestLines)
+		return true
+	})
+
+	if len(r.args) != 1 {
+		t.Fatalf("unexpected commands: %q", r.args)
+	}
+	args := r.args[0]
+	if len(args) != 5 || !reflect.DeepEqual(args[:4], []string{"sh", "-c", `vim -R "$1"`, "sh"}) {
+		t.Errorf("unexpected command: %q", args)
+	}
+	if _, err := os.Stat(args[4]); !os.IsNotExist(err) {
+		t.Errorf("temporary file %s should be removed: %v", args[4], err)
+	}
+	if expected := "\x1b[31mERROR\x1b[0m failed\nretrying\n"; content != expected {
+		t.Errorf("unexpected content of the file: %q, want %q", content, expected)
+	}
+}
@@ -2,47 +2,134 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os/exec"
+	"time"
 
+	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
-	"github.com/ueokande/logbook/pkg/k8s"
-	"github.com/ueokande/logbook/pkg/types"
+	"github.com/ueokande/logbook/pkg/jsonlog"
+	"github.com/ueokande/logbook/pkg/level"
+	"github.com/ueokande/logbook/pkg/source"
 	"github.com/ueokande/logbook/pkg/ui"
-	corev1 "k8s.io/api/core/v1"
+	"github.com/ueokande/logbook/pkg/widgets"
 )
 
 // AppConfig is a config for Logbook App
 type AppConfig struct {
-	Cluster   string
-	Namespace string
+	Cluster    string
+	Namespace  string
+	Selector   string
+	JSONMode   bool
+	JSONFields jsonlog.Fields
+
+	// RecordDir is the directory to record logs of all containers.  Logs are
+	// not recorded if it is empty.
+	RecordDir string
+
+	// RecordMaxSize is the max size of the recorded file in bytes
+	RecordMaxSize int64
+
+	// RecordMaxBackups is the number of rotated files to keep
+	RecordMaxBackups int
+
+	// ClipboardCommand is the command to copy text in addition to OSC 52
+	ClipboardCommand string
+
+	// MaxLines is the max count of the lines kept in the pager.  The lines
+	// are never dropped if it is 0.
+	MaxLines int
+
+	// Workloads shows workloads and their pods in the list instead of pods
+	Workloads bool
+
+	// FollowWorkload enables following the workload of the selected target.
+	// The newest target of the same owner is selected automatically when the
+	// selected target is deleted, such as on rolling update of a Deployment.
+	FollowWorkload bool
+
+	// Keymap is the keys bound to the actions instead of the default keys
+	Keymap *ui.Keymap
+
+	// Wrap, LineNumbers, Timestamps, MinLevel and Follow are the initial
+	// behavior of the pager.  Follow mode is enabled on every selection of
+	// a container.
+	Wrap        bool
+	LineNumbers bool
+	Timestamps  widgets.TimestampFormat
+	MinLevel    level.Level
+	Follow      bool
 }
 
 // App is an application of logbook
 type App struct {
-	client *k8s.Client
+	source source.Source
 	ui     *ui.UI
+	screen *suspendableScreen
+
+	// alternate is the source switched with the source by UI, such as
+	// workloads for pods.  It is nil if the source can not be switched.
+	alternate source.Source
+
+	targets          []*source.Target
+	currentTarget    *source.Target
+	currentContainer string
+	podworker        *Worker
+	logworker        *Worker
+	clipboardCommand string
+
+	// execCommand runs the external command with the terminal while the
+	// screen is suspended.  It is replaced in tests without terminals.
+	execCommand func(cmd *exec.Cmd) error
+
+	// tailID identifies the current tailing to ignore results of the
+	// stopped ones
+	tailID int
+
+	// tailFailed is true if streaming logs of the current container failed,
+	// such as the container has not started yet.  It is retried on the
+	// modification of the target.
+	tailFailed bool
 
-	namespace  string
-	pods       []*corev1.Pod
-	currentPod *corev1.Pod
-	podworker  *Worker
-	logworker  *Worker
+	// currentDeleted is true if the current target has been deleted.  The
+	// deleted target and its logs are kept shown until another target is
+	// selected.
+	currentDeleted bool
+
+	followWorkload bool
+
+	// following is true while switching to the replacement of the deleted
+	// target.  The logs in the pager and the container are kept on
+	// switching.
+	following bool
 
 	*views.Application
 }
 
-// NewApp returns new App instance
-func NewApp(client *k8s.Client, config *AppConfig) *App {
+// NewApp returns new App instance which shows targets and logs from the src
+func NewApp(src source.Source, config *AppConfig) *App {
 	w := ui.NewUI()
 	w.SetContext(config.Cluster, config.Namespace)
 	w.SetStatusMode(ui.ModeNormal)
+	w.SetJSONFields(config.JSONFields)
+	w.SetJSONMode(config.JSONMode)
+	w.SetMaxLines(config.MaxLines)
+	w.SetKeymap(config.Keymap)
+	w.SetWrap(config.Wrap)
+	w.SetGutter(config.LineNumbers)
+	w.SetTimestampFormat(config.Timestamps)
+	w.SetMinLevel(config.MinLevel)
+	w.SetFollowByDefault(config.Follow)
 
 	app := &App{
-		client: client,
+		source: src,
 		ui:     w,
 
-		namespace: config.Namespace,
-		logworker: NewWorker(context.TODO()),
-		podworker: NewWorker(context.TODO()),
+		clipboardCommand: config.ClipboardCommand,
+		execCommand:      execTerminal,
+		followWorkload:   config.FollowWorkload,
+		logworker:        NewWorker(context.TODO()),
+		podworker:        NewWorker(context.TODO()),
 
 		Application: new(views.Application),
 	}
@@ -53,22 +140,120 @@ func NewApp(client *k8s.Client, config *AppConfig) *App {
 	return app
 }
 
+// SetAlternateSource sets the source switched with the current source by UI,
+// such as workloads for pods
+func (app *App) SetAlternateSource(src source.Source) {
+	app.alternate = src
+}
+
+// OnSwitchListRequested handles events on switching the list is required by
+// UI.  The targets are reloaded from the alternate source.
+func (app *App) OnSwitchListRequested() {
+	if app.alternate == nil {
+		app.ui.ShowMessage("No other list to switch")
+		return
+	}
+	app.StopTailPods()
+	app.StopTailLog()
+	app.source, app.alternate = app.alternate, app.source
+
+	app.targets = nil
+	app.currentTarget = nil
+	app.currentContainer = ""
+	app.currentDeleted = false
+	app.tailFailed = false
+	app.ui.ClearPods()
+	app.ui.ClearContainers()
+	app.ui.ClearPager()
+	app.updateWorkload()
+
+	app.StartTailPods()
+}
+
 // OnContainerSelected handles events on container selected by UI
 func (app *App) OnContainerSelected(name string, index int) {
-	pod := app.currentPod
+	if app.currentTarget == nil {
+		return
+	}
+	app.currentContainer = name
+	if !app.following {
+		app.ui.ClearPager()
+	}
+	app.StartTailLog(app.currentTarget.Name, name, time.Time{})
+}
+
+// OnLogsSinceRequested handles events on older logs are required by UI.  It
+// restarts tailing logs of the current container since the time.
+func (app *App) OnLogsSinceRequested(since time.Time) {
+	if app.currentTarget == nil || len(app.currentContainer) == 0 {
+		return
+	}
 	app.ui.ClearPager()
-	app.StartTailLog(pod.Namespace, pod.Name, name)
+	app.StartTailLog(app.currentTarget.Name, app.currentContainer, since)
+}
+
+// OnSaveRequested handles events on saving lines is required by UI.  The
+// lines are written in background, and the result is shown on the status bar.
+func (app *App) OnSaveRequested(path string, lines []widgets.Line, opts ui.SaveOptions) {
+	var prefix string
+	if app.currentTarget != nil {
+		prefix = containerPrefix(app.currentTarget.Name, app.currentContainer)
+	}
+	go func() {
+		n, err := saveLines(path, lines, opts, prefix)
+		app.PostFunc(func() {
+			if err != nil {
+				app.ui.ShowMessage(err.Error())
+				return
+			}
+			app.ui.ShowMessage(fmt.Sprintf("%d bytes written to %s", n, path))
+		})
+	}()
 }
 
-// OnPodSelected handles events on pod selected by UI
+// OnPodSelected handles events on pod selected by UI.  The previous target is
+// removed if it has been deleted.
 func (app *App) OnPodSelected(name string, index int) {
-	app.currentPod = app.pods[index]
-	pod := app.currentPod
+	i := app.findTarget(name)
+	if i < 0 {
+		return
+	}
+	prev, deleted := app.currentTarget, app.currentDeleted
+	app.currentTarget = app.targets[i]
+	app.currentDeleted = false
+	if deleted && prev.Name != name {
+		app.removeTarget(prev.Name)
+	}
+
 	app.ui.ClearContainers()
-	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
-		app.ui.AddContainer(c.Name)
+	// keep the container on switching to the replacement
+	selected := 0
+	for i, name := range app.currentTarget.Containers {
+		app.ui.AddContainer(name)
+		if app.following && name == app.currentContainer {
+			selected = i
+		}
+	}
+	app.ui.SelectContainerAt(selected)
+	app.updateWorkload()
+}
+
+// OnFollowWorkloadRequested handles events on following the workload is
+// required by UI.  It enables or disables following the workload of the
+// current target.
+func (app *App) OnFollowWorkloadRequested() {
+	app.followWorkload = !app.followWorkload
+	app.updateWorkload()
+	switch {
+	case !app.followWorkload:
+		app.ui.ShowMessage("Stopped following the workload")
+	case app.currentTarget == nil || len(app.currentTarget.Owner) == 0:
+		app.ui.ShowMessage("The pod has no workload to follow")
+	case app.currentDeleted:
+		if r := app.findReplacement(); r != nil {
+			app.followTarget(r)
+		}
 	}
-	app.ui.SelectContainerAt(0)
 }
 
 // OnQuit handles events on quit is required by UI
@@ -76,23 +261,34 @@ func (app *App) OnQuit() {
 	app.Quit()
 }
 
-// StartTailLog starts tailing logs for container of pod in namespace
-func (app *App) StartTailLog(namespace, pod, container string) {
+// StartTailLog starts tailing logs for container of the target.  The logs
+// since the time are shown if the since is not zero.
+func (app *App) StartTailLog(target, container string, since time.Time) {
 	app.StopTailLog()
+	app.tailID++
+	app.tailFailed = false
+	id := app.tailID
 
 	app.logworker.Start(func(ctx context.Context) error {
-		logs, err := app.client.WatchLogs(ctx, namespace, pod, container)
+		logs, err := app.source.StreamLogs(ctx, target, container, since)
 		if err != nil {
+			app.PostFunc(func() {
+				if id != app.tailID {
+					return
+				}
+				app.tailFailed = true
+				app.ui.ShowMessage(err.Error())
+			})
 			return err
 		}
 
 		// make channel to guarantee line order of logs
-		ch := make(chan string)
+		ch := make(chan source.LogLine)
 		defer close(ch)
 		for log := range logs {
 			app.PostFunc(func() {
 				for line := range ch {
-					app.ui.AddPagerText(line)
+					app.ui.AddPagerText(line.Text, line.Time)
 					break
 				}
 			})
@@ -112,43 +308,191 @@ func (app *App) StopTailLog() {
 	// TODO handle err
 }
 
-// StartTailPods tarts tailing pods on Kubernetes
+// StartTailPods tarts tailing targets from the source
 func (app *App) StartTailPods() {
 	app.StopTailLog()
 	app.podworker.Start(func(ctx context.Context) error {
-		events, err := app.client.WatchPods(ctx, app.namespace)
+		events, err := app.source.WatchTargets(ctx)
 		if err != nil {
+			app.PostFunc(func() {
+				app.ui.ShowMessage(err.Error())
+			})
 			return err
 		}
+
+		// make channel to guarantee order of events
+		ch := make(chan *source.Event)
+		defer close(ch)
 		for ev := range events {
-			ev := ev
 			app.PostFunc(func() {
-				pod := ev.Pod
-				switch ev.Type {
-				case k8s.PodAdded:
-					app.pods = append(app.pods, pod)
-					app.ui.AddPod(pod.Name, types.GetPodStatus(pod))
-					if len(app.pods) == 1 {
-						app.ui.SelectPodAt(0)
-					}
-				case k8s.PodModified:
-					app.ui.SetPodStatus(pod.Name, types.GetPodStatus(pod))
-				case k8s.PodDeleted:
-					for i, p := range app.pods {
-						if p.Name == pod.Name {
-							app.pods = append(app.pods[:i], app.pods[i+1:]...)
-							break
-						}
-					}
-					app.ui.DeletePod(pod.Name)
+				for ev := range ch {
+					app.handleTargetEvent(ev)
+					break
 				}
 			})
-
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return nil
+			}
 		}
 		return nil
 	})
 }
 
+func (app *App) handleTargetEvent(ev *source.Event) {
+	if ev.Err != nil {
+		app.ui.ShowMessage(ev.Err.Error())
+	}
+	target := ev.Target
+	switch ev.Type {
+	case source.TargetAdded:
+		if i := app.findTarget(target.Name); i >= 0 {
+			// the deleted target is re-created with the same name, such as a
+			// pod of the StatefulSet
+			app.targets[i] = target
+			app.ui.SetPodStatus(target.Name, target.Status)
+			if app.currentTarget != nil && app.currentTarget.Name == target.Name {
+				app.currentTarget = target
+				app.currentDeleted = false
+				app.ui.AddPagerText(fmt.Sprintf("---- %s is re-created ----", target.Name), time.Time{})
+				app.StartTailLog(target.Name, app.currentContainer, time.Time{})
+			}
+			break
+		}
+
+		app.targets = append(app.targets, target)
+		app.ui.AddNestedPod(target.Name, target.Parent, target.Status)
+		if target.Virtual {
+			app.ui.MarkPodVirtual(target.Name)
+			break
+		}
+		if app.currentTarget == nil {
+			app.ui.SelectPod(target.Name)
+			break
+		}
+		if app.currentDeleted && app.followWorkload {
+			if r := app.findReplacement(); r != nil {
+				app.followTarget(r)
+			}
+		}
+	case source.TargetModified:
+		i := app.findTarget(target.Name)
+		if i < 0 {
+			break
+		}
+		if app.targets[i].Parent != target.Parent {
+			app.ui.MovePod(target.Name, target.Parent)
+		}
+		app.targets[i] = target
+		app.ui.SetPodStatus(target.Name, target.Status)
+		if app.currentTarget != nil && app.currentTarget.Name == target.Name {
+			app.addContainers(target)
+			app.currentTarget = target
+			if app.tailFailed {
+				app.StartTailLog(target.Name, app.currentContainer, time.Time{})
+			}
+		}
+	case source.TargetDeleted:
+		if app.currentTarget != nil && app.currentTarget.Name == target.Name {
+			app.StopTailLog()
+			app.currentDeleted = true
+			app.ui.MarkPodDeleted(target.Name)
+			app.ui.ShowMessage(target.Name + " is deleted")
+			if app.followWorkload {
+				if r := app.findReplacement(); r != nil {
+					app.followTarget(r)
+				}
+			}
+			break
+		}
+		app.removeTarget(target.Name)
+	}
+}
+
+// findTarget returns the index of the target by the name.  It returns -1 if
+// not found.
+func (app *App) findTarget(name string) int {
+	for i, t := range app.targets {
+		if t.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// findReplacement returns the newest target of the same owner as the current
+// target, which has the current container.  It returns nil if not found.
+func (app *App) findReplacement() *source.Target {
+	owner := app.currentTarget.Owner
+	if len(owner) == 0 {
+		return nil
+	}
+	var found *source.Target
+	for _, t := range app.targets {
+		if t.Name == app.currentTarget.Name || t.Owner != owner || !hasContainer(t, app.currentContainer) {
+			continue
+		}
+		if found == nil || t.CreatedAt.After(found.CreatedAt) {
+			found = t
+		}
+	}
+	return found
+}
+
+// followTarget switches from the deleted current target to the target.  The
+// logs of the deleted target are kept in the pager followed by a separator.
+func (app *App) followTarget(target *source.Target) {
+	app.ui.AddPagerText(fmt.Sprintf("---- %s is deleted, following %s ----", app.currentTarget.Name, target.Name), time.Time{})
+	app.following = true
+	app.ui.SelectPod(target.Name)
+	app.following = false
+}
+
+// updateWorkload shows the workload of the current target on the status bar
+// if the workload is followed
+func (app *App) updateWorkload() {
+	var workload string
+	if app.followWorkload && app.currentTarget != nil {
+		workload = app.currentTarget.Owner
+	}
+	app.ui.SetWorkload(workload)
+}
+
+// addContainers adds the containers of the target missing in the current
+// target to the tabs, such as the log files of the node listed after the node
+// is added.  The first container is selected if no containers are selected.
+func (app *App) addContainers(target *source.Target) {
+	empty := len(app.currentTarget.Containers) == 0
+	for _, c := range target.Containers {
+		if !hasContainer(app.currentTarget, c) {
+			app.ui.AddContainer(c)
+		}
+	}
+	if empty && len(target.Containers) > 0 {
+		app.ui.SelectContainerAt(0)
+	}
+}
+
+func hasContainer(target *source.Target, container string) bool {
+	for _, c := range target.Containers {
+		if c == container {
+			return true
+		}
+	}
+	return false
+}
+
+// removeTarget removes the target by the name from the targets and the UI
+func (app *App) removeTarget(name string) {
+	i := app.findTarget(name)
+	if i < 0 {
+		return
+	}
+	app.targets = append(app.targets[:i], app.targets[i+1:]...)
+	app.ui.DeletePod(name)
+}
+
 // StopTailPods stops tailing pods
 func (app *App) StopTailPods() {
 	app.podworker.Stop()
@@ -157,6 +501,27 @@ func (app *App) StopTailPods() {
 
 // Run starts logbook application
 func (app *App) Run(ctx context.Context) error {
+	screen, err := tcell.NewScreen()
+	if err != nil {
+		return err
+	}
+	app.start(screen)
+	return app.wait()
+}
+
+// start starts the app on the screen in background
+func (app *App) start(screen tcell.Screen) {
+	app.screen = newSuspendableScreen(screen)
+	app.SetScreen(app.screen)
+
 	app.StartTailPods()
-	return app.Application.Run()
+	app.Application.Start()
+}
+
+// wait waits for the app to quit, and stops tailing targets and logs
+func (app *App) wait() error {
+	err := app.Application.Wait()
+	app.StopTailLog()
+	app.StopTailPods()
+	return err
 }
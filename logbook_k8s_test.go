@@ -0,0 +1,550 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/source"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// logServer serves logs of containers as Kubernetes API.  It responds 400
+// for containers not started.
+type logServer struct {
+	mu     sync.Mutex
+	logs   map[string][]string
+	notify chan struct{}
+
+	*httptest.Server
+}
+
+func newLogServer() *logServer {
+	s := &logServer{
+		logs:   make(map[string][]string),
+		notify: make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveLogs))
+	return s
+}
+
+// appendLogs starts the container if not started, and appends the lines
+func (s *logServer) appendLogs(pod, container string, lines ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := pod + "/" + container
+	for _, line := range lines {
+		s.logs[key] = append(s.logs[key], time.Now().UTC().Format(time.RFC3339Nano)+" "+line)
+	}
+	if s.logs[key] == nil {
+		s.logs[key] = []string{}
+	}
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+func (s *logServer) serveLogs(w http.ResponseWriter, r *http.Request) {
+	// /api/v1/namespaces/{namespace}/pods/{pod}/log
+	parts := strings.Split(r.URL.Path, "/")
+	if len(parts) != 8 || parts[7] != "log" {
+		http.NotFound(w, r)
+		return
+	}
+	container := r.URL.Query().Get("container")
+	key := parts[6] + "/" + container
+
+	var i int
+	for {
+		s.mu.Lock()
+		lines, ok := s.logs[key]
+		notify := s.notify
+		s.mu.Unlock()
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"container %q in pod %q is waiting to start: ContainerCreating","reason":"BadRequest","code":400}`, container, parts[6])
+			return
+		}
+
+		for _, line := range lines[i:] {
+			fmt.Fprintln(w, line)
+		}
+		i = len(lines)
+		w.(http.Flusher).Flush()
+		if r.URL.Query().Get("follow") != "true" {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// testClientset is a fake clientset which gets logs from the log server
+type testClientset struct {
+	*fake.Clientset
+	logs kubernetes.Interface
+}
+
+func (c *testClientset) CoreV1() corev1client.CoreV1Interface {
+	return &testCoreV1{CoreV1Interface: c.Clientset.CoreV1(), logs: c.logs.CoreV1()}
+}
+
+type testCoreV1 struct {
+	corev1client.CoreV1Interface
+	logs corev1client.CoreV1Interface
+}
+
+func (c *testCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &testPods{PodInterface: c.CoreV1Interface.Pods(namespace), logs: c.logs.Pods(namespace)}
+}
+
+type testPods struct {
+	corev1client.PodInterface
+	logs corev1client.PodInterface
+}
+
+func (p *testPods) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	return p.logs.GetLogs(name, opts)
+}
+
+// k8sTest is an app running with the fake clientset on the simulation screen
+type k8sTest struct {
+	t         *testing.T
+	app       *App
+	screen    tcell.SimulationScreen
+	clientset *fake.Clientset
+	logs      *logServer
+}
+
+func newK8sTest(t *testing.T, config *AppConfig) *k8sTest {
+	logs := newLogServer()
+	logClientset, err := kubernetes.NewForConfig(&rest.Config{Host: logs.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewSimpleClientset()
+	client := k8s.NewClientForClientset(&testClientset{Clientset: clientset, logs: logClientset})
+
+	screen := tcell.NewSimulationScreen("")
+	screen.SetSize(80, 12)
+	pods := source.NewKubernetes(client, "default", "")
+	workloads := source.NewWorkloads(client, "default", "")
+	var app *App
+	if config.Workloads {
+		app = NewApp(workloads, config)
+		app.SetAlternateSource(pods)
+	} else {
+		app = NewApp(pods, config)
+		app.SetAlternateSource(workloads)
+	}
+	app.start(screen)
+
+	test := &k8sTest{t: t, app: app, screen: screen, clientset: clientset, logs: logs}
+
+	// events of objects created before watching are not sent by the fake
+	watches := 1
+	if config.Workloads {
+		watches = 6
+	}
+	test.waitForWatches(watches)
+	return test
+}
+
+// waitForWatches waits for the count of the watches started
+func (test *k8sTest) waitForWatches(n int) {
+	test.t.Helper()
+	test.waitFor(func() bool {
+		var count int
+		for _, action := range test.clientset.Actions() {
+			if action.GetVerb() == "watch" {
+				count++
+			}
+		}
+		return count >= n
+	})
+}
+
+func (test *k8sTest) close() {
+	test.app.Quit()
+	if err := test.app.wait(); err != nil {
+		test.t.Error(err)
+	}
+	test.logs.Close()
+}
+
+func (test *k8sTest) waitFor(cond func() bool) {
+	test.t.Helper()
+	waitForApp(test.t, test.app, cond)
+}
+
+func (test *k8sTest) waitForText(text string) {
+	test.t.Helper()
+	waitForText(test.t, test.app, test.screen, text)
+}
+
+func (test *k8sTest) createPod(pod *corev1.Pod) {
+	if _, err := test.clientset.CoreV1().Pods("default").Create(pod); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+func (test *k8sTest) updatePod(pod *corev1.Pod) {
+	if _, err := test.clientset.CoreV1().Pods("default").Update(pod); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+// createReplicaSet creates the ReplicaSet owned by the Deployment
+func (test *k8sTest) createReplicaSet(name, deployment string) {
+	controller := true
+	rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+		Name:      name,
+		Namespace: "default",
+		OwnerReferences: []metav1.OwnerReference{
+			{Kind: "Deployment", Name: deployment, Controller: &controller},
+		},
+	}}
+	if _, err := test.clientset.AppsV1().ReplicaSets("default").Create(rs); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+func (test *k8sTest) createDeployment(name string, containers ...string) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+	for _, c := range containers {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, corev1.Container{Name: c})
+	}
+	if _, err := test.clientset.AppsV1().Deployments("default").Create(deploy); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+// createEvent creates the event of the pod
+func (test *k8sTest) createEvent(name, pod, eventType, reason, message string, count int32) {
+	ev := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod},
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Count:          count,
+		LastTimestamp:  metav1.Now(),
+	}
+	if _, err := test.clientset.CoreV1().Events("default").Create(ev); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+func (test *k8sTest) deletePod(name string) {
+	if err := test.clientset.CoreV1().Pods("default").Delete(name, &metav1.DeleteOptions{}); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+func runningPod(name string, containers ...string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	for _, c := range containers {
+		pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: c})
+		pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses, corev1.ContainerStatus{
+			Name:  c,
+			Ready: true,
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		})
+	}
+	return pod
+}
+
+// ownedPod returns a running pod controlled by the ReplicaSet
+func ownedPod(name, replicaSet string, containers ...string) *corev1.Pod {
+	pod := runningPod(name, containers...)
+	controller := true
+	pod.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "ReplicaSet", Name: replicaSet, Controller: &controller},
+	}
+	return pod
+}
+
+func TestAppPodsAddedAndDeleted(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.logs.appendLogs("nginx", "app", "hello from nginx")
+	test.createPod(runningPod("nginx", "app"))
+	test.createPod(runningPod("redis", "redis"))
+	test.waitForText(" 2 Pods ")
+	test.waitForText("hello from nginx")
+	test.waitForText("redis")
+
+	test.deletePod("redis")
+	test.waitForText(" 1 Pods ")
+	test.waitFor(func() bool {
+		return len(test.app.targets) == 2 && test.app.findTarget("redis") < 0
+	})
+}
+
+func TestAppContainerNotStarted(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+	test.createPod(pod)
+	test.waitFor(func() bool {
+		return test.app.tailFailed
+	})
+
+	// logs are streamed after the container started
+	test.logs.appendLogs("nginx", "app", "started")
+	test.updatePod(runningPod("nginx", "app"))
+	test.waitForText("started")
+}
+
+func TestAppSelectedPodDeleted(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.logs.appendLogs("nginx", "app", "hello from nginx")
+	test.logs.appendLogs("redis", "redis", "hello from redis")
+	test.createPod(runningPod("nginx", "app"))
+	test.createPod(runningPod("redis", "redis"))
+	test.waitForText("hello from nginx")
+
+	// the deleted pod and its logs are kept until another pod is selected
+	test.deletePod("nginx")
+	test.waitForText("nginx (deleted)")
+	test.waitForText(" 1 Pods ")
+	test.waitFor(func() bool {
+		return test.app.currentDeleted && test.app.currentTarget.Name == "nginx"
+	})
+	test.logs.appendLogs("nginx", "app", "after deleted")
+	time.Sleep(100 * time.Millisecond)
+	test.waitFor(func() bool {
+		text := screenText(test.screen)
+		return strings.Contains(text, "hello from nginx") && !strings.Contains(text, "after deleted")
+	})
+
+	test.screen.InjectKey(tcell.KeyCtrlN, 0, tcell.ModNone)
+	test.waitForText("hello from redis")
+	test.waitFor(func() bool {
+		return len(test.app.targets) == 2 && !test.app.currentDeleted &&
+			test.app.currentTarget.Name == "redis"
+	})
+	test.waitFor(func() bool {
+		return !strings.Contains(screenText(test.screen), "(deleted)")
+	})
+}
+
+func TestAppSelectedPodRecreated(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.logs.appendLogs("web-0", "app", "hello from web-0")
+	test.createPod(runningPod("web-0", "app"))
+	test.waitForText("hello from web-0")
+
+	// the pod of the StatefulSet is re-created with the same name
+	test.deletePod("web-0")
+	test.waitForText("web-0 (deleted)")
+	test.logs.appendLogs("web-0", "app", "restarted")
+	test.createPod(runningPod("web-0", "app"))
+	test.waitForText("restarted")
+	test.waitForText(" 1 Pods ")
+	test.waitFor(func() bool {
+		return !test.app.currentDeleted && len(test.app.targets) == 2
+	})
+}
+
+func TestAppFollowWorkload(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{FollowWorkload: true})
+	defer test.close()
+
+	test.logs.appendLogs("web-abcde", "app", "hello from web-abcde")
+	test.logs.appendLogs("web-fghij", "app", "hello from web-fghij")
+	test.createPod(ownedPod("web-abcde", "web-5d4f", "app"))
+	test.createPod(runningPod("redis", "redis"))
+	test.waitForText("hello from web-abcde")
+	test.waitForText(" ReplicaSet/web-5d4f ")
+
+	// no pods of the workload exist on deleted
+	test.deletePod("web-abcde")
+	test.waitForText("web-abcde (deleted)")
+
+	// pods of other workloads are not followed
+	test.createPod(ownedPod("db-klmno", "db-8a7b", "db"))
+	test.waitForText("db-klmno")
+	test.waitFor(func() bool {
+		return test.app.currentDeleted && test.app.currentTarget.Name == "web-abcde"
+	})
+
+	test.createPod(ownedPod("web-fghij", "web-5d4f", "app"))
+	test.waitForText("hello from web-fghij")
+	test.waitForText("---- web-abcde is deleted, following web-fghij ----")
+	test.waitForText("hello from web-abcde")
+	test.waitFor(func() bool {
+		return !test.app.currentDeleted && test.app.currentTarget.Name == "web-fghij" &&
+			len(test.app.targets) == 4
+	})
+	test.waitFor(func() bool {
+		return !strings.Contains(screenText(test.screen), "(deleted)")
+	})
+}
+
+func TestAppFollowWorkloadRollingUpdate(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.createReplicaSet("web-5d4f", "web")
+	test.createReplicaSet("web-8a7b", "web")
+	test.logs.appendLogs("web-abcde", "app", "hello from web-abcde")
+	test.logs.appendLogs("web-fghij", "app", "hello from web-fghij")
+	test.logs.appendLogs("web-klmno", "app", "hello from web-klmno")
+
+	old := ownedPod("web-abcde", "web-5d4f", "istio-proxy", "app")
+	old.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	test.createPod(old)
+	test.waitForText("istio-proxy")
+	test.screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	test.waitForText("hello from web-abcde")
+
+	test.screen.InjectKey(tcell.KeyRune, 'W', tcell.ModNone)
+	test.waitForText(" Deployment/web ")
+
+	// the newest pod of the workload is followed
+	newer := ownedPod("web-fghij", "web-8a7b", "istio-proxy", "app")
+	newer.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Minute))
+	test.createPod(newer)
+	newest := ownedPod("web-klmno", "web-8a7b", "istio-proxy", "app")
+	newest.CreationTimestamp = metav1.NewTime(time.Now())
+	test.createPod(newest)
+	test.waitForText("web-klmno")
+	test.deletePod("web-abcde")
+
+	test.waitForText("hello from web-klmno")
+	test.waitForText("---- web-abcde is deleted, following web-klmno ----")
+	test.waitFor(func() bool {
+		return test.app.currentTarget.Name == "web-klmno" && test.app.currentContainer == "app"
+	})
+
+	test.screen.InjectKey(tcell.KeyRune, 'W', tcell.ModNone)
+	test.waitForText("Stopped following the workload")
+	test.waitFor(func() bool {
+		return !strings.Contains(screenText(test.screen), " Deployment/web ")
+	})
+}
+
+func TestAppWorkloads(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{Workloads: true})
+	defer test.close()
+
+	test.createDeployment("web", "app")
+	test.waitForText("Deployment/web")
+
+	// the workload selected first streams logs of its pods
+	test.waitForWatches(7)
+	test.createReplicaSet("web-5d4f", "web")
+	test.logs.appendLogs("web-abcde", "app", "hello from web-abcde")
+	test.logs.appendLogs("web-fghij", "app", "hello from web-fghij")
+	test.createPod(ownedPod("web-abcde", "web-5d4f", "app"))
+	test.createPod(runningPod("redis", "redis"))
+	test.createPod(ownedPod("web-fghij", "web-5d4f", "app"))
+	test.waitForText("[web-abcde] hello from web-abcde")
+	test.waitForText("[web-fghij] hello from web-fghij")
+	test.waitFor(func() bool {
+		rows := strings.Split(screenText(test.screen), "\n")
+		return strings.HasPrefix(rows[0], "Events") &&
+			strings.HasPrefix(rows[1], "Deployment/web") &&
+			strings.HasPrefix(rows[2], "  web-abcde") &&
+			strings.HasPrefix(rows[3], "  web-fghij") &&
+			strings.HasPrefix(rows[4], "redis")
+	})
+
+	// the pod nested beneath the workload shows its own logs
+	test.screen.InjectKey(tcell.KeyCtrlN, 0, tcell.ModNone)
+	test.waitFor(func() bool {
+		text := screenText(test.screen)
+		return test.app.currentTarget.Name == "web-abcde" &&
+			strings.Contains(text, "│hello from web-abcde") && !strings.Contains(text, "web-fghij]")
+	})
+
+	// the list is switched to pods
+	test.screen.InjectKey(tcell.KeyCtrlW, 0, tcell.ModNone)
+	test.waitForText(" 0 Pods ")
+	test.waitForWatches(8)
+	test.createPod(runningPod("nginx", "app"))
+	test.waitFor(func() bool {
+		return len(test.app.targets) == 2 && test.app.currentTarget.Name == "nginx"
+	})
+}
+
+func TestAppEvents(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.createEvent("nginx.1", "nginx", "Warning", "BackOff", "Back-off restarting failed container", 3)
+	test.createEvent("redis.1", "redis", "Normal", "Pulled", "Container image pulled", 1)
+	test.logs.appendLogs("nginx", "app", "hello from nginx")
+	test.createPod(runningPod("nginx", "app"))
+	test.waitForText("hello from nginx")
+	test.waitForText(" 1 Pods ")
+
+	// events of the pod are streamed in the virtual container
+	test.screen.InjectKey(tcell.KeyTab, 0, tcell.ModNone)
+	test.waitForText("[Warning] BackOff Pod/nginx: Back-off restarting failed container (x3)")
+	test.waitFor(func() bool {
+		return test.app.currentContainer == source.Events && !strings.Contains(screenText(test.screen), "Pod/redis")
+	})
+
+	// the virtual target streams events in the namespace
+	test.screen.InjectKey(tcell.KeyCtrlP, 0, tcell.ModNone)
+	test.waitForText("[Normal] Pulled Pod/redis: Container image pulled")
+	test.waitForText("[Warning] BackOff Pod/nginx:")
+	test.waitFor(func() bool {
+		return test.app.currentTarget.Name == source.Events
+	})
+}
+
+func TestAppFollowLogs(t *testing.T) {
+	test := newK8sTest(t, &AppConfig{})
+	defer test.close()
+
+	test.logs.appendLogs("nginx", "app", "line 1")
+	test.createPod(runningPod("nginx", "app"))
+	test.waitForText("line 1")
+
+	test.screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+	test.waitForText(" FOLLOW ")
+	for i := 2; i <= 30; i++ {
+		test.logs.appendLogs("nginx", "app", fmt.Sprintf("line %d", i))
+	}
+	test.waitFor(func() bool {
+		rows := strings.Split(screenText(test.screen), "\n")
+		// the last line is shown above the status bar
+		return strings.Contains(rows[len(rows)-3], "line 30")
+	})
+
+	test.screen.InjectKey(tcell.KeyRune, 'f', tcell.ModNone)
+	test.waitForText(" NORMAL ")
+}
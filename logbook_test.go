@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/source"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+// screenText returns the text on the screen with rows separated by newlines
+func screenText(screen tcell.SimulationScreen) string {
+	cells, width, _ := screen.GetContents()
+	var b strings.Builder
+	for i, c := range cells {
+		if len(c.Runes) > 0 {
+			b.WriteRune(c.Runes[0])
+		} else {
+			b.WriteByte(' ')
+		}
+		if (i+1)%width == 0 {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// waitForText waits for the text to be shown on the screen of the app
+func waitForText(t *testing.T, app *App, screen tcell.SimulationScreen, text string) {
+	t.Helper()
+	waitForApp(t, app, func() bool {
+		return strings.Contains(screenText(screen), text)
+	})
+}
+
+// waitForApp waits for cond to be true.  The cond is evaluated in the event
+// loop of the app, because the screen and the app are not goroutine-safe.
+func waitForApp(t *testing.T, app *App, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		ch := make(chan bool)
+		app.PostFunc(func() {
+			ch <- cond()
+		})
+		if <-ch {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out")
+}
+
+func TestAppWithMemorySource(t *testing.T) {
+	src := source.NewMemory()
+	src.AddTarget(source.Target{Name: "nginx", Status: types.PodRunning, Containers: []string{"app", "sidecar"}})
+	src.AddTarget(source.Target{Name: "redis", Status: types.PodPending, Containers: []string{"redis"}})
+	src.AppendLogs("nginx", "app", source.LogLine{Text: "hello from app"})
+	src.AppendLogs("nginx", "sidecar", source.LogLine{Text: "hello from sidecar"})
+
+	screen := tcell.NewSimulationScreen("")
+	screen.SetSize(80, 24)
+	app := NewApp(src, &AppConfig{})
+
+	app.start(screen)
+
+	waitForText(t, app, screen, "redis")
+	waitForText(t, app, screen, "hello from app")
+
+	// lines appended later are streamed
+	src.AppendLogs("nginx", "app", source.LogLine{Text: "bye from app"})
+	waitForText(t, app, screen, "bye from app")
+
+	app.PostFunc(func() {
+		app.ui.SelectContainerAt(1)
+	})
+	waitForText(t, app, screen, "hello from sidecar")
+
+	src.DeleteTarget("redis")
+	waitForApp(t, app, func() bool {
+		return len(app.targets) == 1
+	})
+	app.Quit()
+	if err := app.wait(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -6,7 +6,15 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/ueokande/logbook/pkg/config"
+	"github.com/ueokande/logbook/pkg/jsonlog"
 	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/level"
+	"github.com/ueokande/logbook/pkg/record"
+	"github.com/ueokande/logbook/pkg/source"
+	"github.com/ueokande/logbook/pkg/ui"
+	"github.com/ueokande/logbook/pkg/widgets"
 )
 
 var homedir string
@@ -23,53 +31,198 @@ func init() {
 type params struct {
 	namespace  string
 	kubeconfig string
+	selector   string
+	noTUI      bool
+	json       bool
+	jsonFields jsonlog.Fields
+
+	recordDir        string
+	recordMaxSize    int64
+	recordMaxBackups int
+
+	clipboardCommand string
+	maxLines         int
+
+	followWorkload bool
+	workloads      bool
+
+	// configPath is the path of the config file, and the rest are loaded
+	// from the file
+	configPath  string
+	keymap      *ui.Keymap
+	wrap        bool
+	lineNumbers bool
+	timestamps  widgets.TimestampFormat
+	minLevel    level.Level
+	follow      bool
 }
 
 func main() {
-	p := params{}
+	p := params{
+		jsonFields: jsonlog.DefaultFields,
+
+		recordMaxSize:    100,
+		recordMaxBackups: 5,
+
+		configPath: config.DefaultPath(),
+	}
 
 	cmd := &cobra.Command{}
+	cmd.Use = "logbook"
 	cmd.Short = "View logs on multiple pods and containers from Kubernetes"
 
-	cmd.Flags().StringVarP(&p.namespace, "namespace", "n", p.namespace, "Kubernetes namespace to use. Default to namespace configured in Kubernetes context")
-	cmd.Flags().StringVarP(&p.kubeconfig, "kubeconfig", "", p.kubeconfig, " Path to kubeconfig file to use")
+	cmd.PersistentFlags().StringVarP(&p.configPath, "config", "", p.configPath, "Path to the config file")
+	cmd.PersistentFlags().StringVarP(&p.namespace, "namespace", "n", p.namespace, "Kubernetes namespace to use. Default to namespace configured in Kubernetes context")
+	cmd.PersistentFlags().StringVarP(&p.kubeconfig, "kubeconfig", "", p.kubeconfig, " Path to kubeconfig file to use")
+	cmd.PersistentFlags().StringVarP(&p.selector, "selector", "l", p.selector, "Label selector of pods, such as \"app=nginx\"")
+	cmd.Flags().BoolVarP(&p.noTUI, "no-tui", "", p.noTUI, "Stream logs of all pods to stdout without UI, same as \"tail\" command")
+	cmd.Flags().BoolVarP(&p.workloads, "workloads", "", p.workloads, "List workloads, such as Deployments, and their pods instead of pods")
+	cmd.Flags().BoolVarP(&p.followWorkload, "follow-workload", "", p.followWorkload, "Follow the workload of the selected pod, and select the newest pod of the workload when the pod is deleted")
+	p.addUIFlags(cmd.Flags())
 
+	cmd.Flags().StringVarP(&p.recordDir, "record", "", p.recordDir, "Record logs of all containers into the directory")
+	cmd.Flags().Int64VarP(&p.recordMaxSize, "record-max-size", "", p.recordMaxSize, "Max size in megabytes of the recorded file before it gets rotated")
+	cmd.Flags().IntVarP(&p.recordMaxBackups, "record-max-backups", "", p.recordMaxBackups, "Number of rotated files to keep")
+
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		return p.loadConfigFile(cmd, c)
+	}
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
-		context, err := k8s.LoadCurrentContext(p.kubeconfig)
+		client, config, err := p.loadConfig()
 		if err != nil {
 			return err
 		}
 
-		client, err := k8s.NewClient(p.kubeconfig)
-		if err != nil {
-			return err
+		if p.noTUI {
+			return tail(ctx, client, os.Stdout, tailOptions{
+				namespace: config.Namespace,
+				selector:  config.Selector,
+				prefix:    true,
+			})
 		}
 
-		config := &AppConfig{
-			Cluster:   context.Cluster,
-			Namespace: "default",
-		}
-		if len(context.Namespace) > 0 {
-			config.Namespace = context.Namespace
-		}
-		if len(p.namespace) > 0 {
-			config.Namespace = p.namespace
-		}
-
-		app := NewApp(client, config)
-		err = app.Run(ctx)
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return runKubernetes(ctx, client, config)
 	}
+	cmd.SilenceErrors = true
+	cmd.SilenceUsage = true
+
+	cmd.AddCommand(newTailCommand(&p))
+	cmd.AddCommand(newPodsCommand(&p))
+	cmd.AddCommand(newContainersCommand(&p))
+	cmd.AddCommand(newDumpCommand(&p))
+	cmd.AddCommand(newViewCommand(&p))
+	cmd.AddCommand(newDockerCommand(&p))
+	cmd.AddCommand(newNodesCommand(&p))
 
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if err, ok := err.(*exitError); ok {
+			os.Exit(err.code)
+		}
 		os.Exit(1)
 	}
 }
+
+// addUIFlags adds flags of the UI to the flags
+func (p *params) addUIFlags(flags *pflag.FlagSet) {
+	flags.BoolVarP(&p.json, "json", "", p.json, "Render JSON logs as \"time level msg key=value ...\"")
+	flags.StringSliceVarP(&p.jsonFields.Time, "json-time-fields", "", p.jsonFields.Time, "Field names of the time in JSON logs")
+	flags.StringSliceVarP(&p.jsonFields.Level, "json-level-fields", "", p.jsonFields.Level, "Field names of the level in JSON logs")
+	flags.StringSliceVarP(&p.jsonFields.Message, "json-message-fields", "", p.jsonFields.Message, "Field names of the message in JSON logs")
+	flags.StringVarP(&p.clipboardCommand, "clipboard-command", "", p.clipboardCommand, "Command to copy text from stdin in addition to OSC 52, such as \"pbcopy\", for terminals not supporting OSC 52")
+	flags.IntVarP(&p.maxLines, "max-lines", "", p.maxLines, "Max count of lines kept in the pager.  The oldest lines are dropped if exceeded (0 for unlimited)")
+}
+
+// appConfig returns the config of the app by the parameters without
+// Kubernetes context
+func (p *params) appConfig() *AppConfig {
+	return &AppConfig{
+		Namespace:  "default",
+		Selector:   p.selector,
+		JSONMode:   p.json,
+		JSONFields: p.jsonFields,
+
+		RecordDir:        p.recordDir,
+		RecordMaxSize:    p.recordMaxSize * 1024 * 1024,
+		RecordMaxBackups: p.recordMaxBackups,
+		ClipboardCommand: p.clipboardCommand,
+		MaxLines:         p.maxLines,
+
+		FollowWorkload: p.followWorkload,
+		Workloads:      p.workloads,
+
+		Keymap:      p.keymap,
+		Wrap:        p.wrap,
+		LineNumbers: p.lineNumbers,
+		Timestamps:  p.timestamps,
+		MinLevel:    p.minLevel,
+		Follow:      p.follow,
+	}
+}
+
+// loadConfig loads kubeconfig and returns the client and the config of the
+// app by the parameters
+func (p *params) loadConfig() (*k8s.Client, *AppConfig, error) {
+	context, err := k8s.LoadCurrentContext(p.kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := k8s.NewClient(p.kubeconfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	config := p.appConfig()
+	config.Cluster = context.Cluster
+	if len(context.Namespace) > 0 {
+		config.Namespace = context.Namespace
+	}
+	if len(p.namespace) > 0 {
+		config.Namespace = p.namespace
+	}
+	return client, config, nil
+}
+
+// runKubernetes runs the app showing pods in Kubernetes.  The recording logs
+// are flushed on quit.
+func runKubernetes(ctx context.Context, client *k8s.Client, config *AppConfig) error {
+	pods := source.NewKubernetes(client, config.Namespace, config.Selector)
+	workloads := source.NewWorkloads(client, config.Namespace, config.Selector)
+
+	var recorder *record.Recorder
+	if len(config.RecordDir) > 0 {
+		recorder = record.NewRecorder(client, config.RecordDir, config.RecordMaxSize, config.RecordMaxBackups)
+		pods.OnPodChanged(recorder.RecordPod)
+		workloads.OnPodChanged(recorder.RecordPod)
+	}
+
+	var app *App
+	if config.Workloads {
+		app = NewApp(workloads, config)
+		app.SetAlternateSource(pods)
+	} else {
+		app = NewApp(pods, config)
+		app.SetAlternateSource(workloads)
+	}
+	err := app.Run(ctx)
+	if recorder != nil {
+		if rerr := recorder.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// exitError is an error with the exit code of the process
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/ueokande/logbook/pkg/source"
+)
+
+func newNodesCommand(p *params) *cobra.Command {
+	services := []string{"kubelet"}
+
+	cmd := &cobra.Command{}
+	cmd.Use = "nodes"
+	cmd.Short = "View logs of nodes via the kubelet proxy"
+	cmd.Long = `View logs of nodes via the kubelet proxy.  The services on the node, such
+as kubelet, and the files in /var/log of the node are shown in the tabs.  The
+logs of the services are queried by the node log query, which requires
+NodeLogQuery feature of the kubelet.`
+	cmd.Args = cobra.NoArgs
+	cmd.Flags().StringSliceVarP(&services, "service", "", services, "Names of the services on the node to query logs")
+	p.addUIFlags(cmd.Flags())
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		client, config, err := p.loadConfig()
+		if err != nil {
+			return err
+		}
+		config.Namespace = "nodes"
+
+		return NewApp(source.NewNodes(client, services), config).Run(context.Background())
+	}
+	return cmd
+}
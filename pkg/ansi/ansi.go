@@ -0,0 +1,173 @@
+package ansi
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+)
+
+const esc = '\x1b'
+
+// Parse parses ANSI escape sequences in s.  It returns the text without
+// escape sequences and the style of each rune in the text.  The style is
+// the initial style of the text, and it is also used on the SGR reset.  The
+// SGR sequences are converted into the styles, and other escape sequences are
+// just removed.
+func Parse(s string, style tcell.Style) ([]rune, []tcell.Style) {
+	base := style
+	runes := make([]rune, 0, len(s))
+	styles := make([]tcell.Style, 0, len(s))
+	for i := 0; i < len(s); {
+		if s[i] != esc {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			runes = append(runes, r)
+			styles = append(styles, style)
+			i += size
+			continue
+		}
+
+		params, final, n := scanEscape(s[i:])
+		if final == 'm' {
+			style = applySGR(style, base, parseParams(params))
+		}
+		i += n
+	}
+	return runes, styles
+}
+
+// Strip removes ANSI escape sequences in s.
+func Strip(s string) string {
+	if strings.IndexByte(s, esc) == -1 {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != esc {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		_, _, n := scanEscape(s[i:])
+		i += n
+	}
+	return b.String()
+}
+
+// scanEscape scans an escape sequence on the head of s.  It returns the
+// parameters and the final byte of the sequence if the sequence is a CSI,
+// and the length of the sequence.
+func scanEscape(s string) (string, byte, int) {
+	if len(s) < 2 {
+		return "", 0, len(s)
+	}
+	switch s[1] {
+	case '[':
+		// CSI: ESC [ parameters intermediates final
+		for j := 2; j < len(s); j++ {
+			if s[j] >= 0x40 && s[j] <= 0x7e {
+				return s[2:j], s[j], j + 1
+			}
+		}
+		return "", 0, len(s)
+	case ']':
+		// OSC: terminated by BEL or ST (ESC \)
+		for j := 2; j < len(s); j++ {
+			if s[j] == '\a' {
+				return "", 0, j + 1
+			}
+			if s[j] == esc && j+1 < len(s) && s[j+1] == '\\' {
+				return "", 0, j + 2
+			}
+		}
+		return "", 0, len(s)
+	}
+	return "", 0, 2
+}
+
+func parseParams(params string) []int {
+	if len(params) == 0 {
+		return []int{0}
+	}
+	fields := strings.FieldsFunc(params, func(c rune) bool {
+		return c == ';' || c == ':'
+	})
+	codes := make([]int, 0, len(fields))
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			n = 0
+		}
+		codes = append(codes, n)
+	}
+	return codes
+}
+
+func applySGR(style, base tcell.Style, codes []int) tcell.Style {
+	basefg, basebg, _ := base.Decompose()
+	for i := 0; i < len(codes); i++ {
+		c := codes[i]
+		switch {
+		case c == 0:
+			style = base
+		case c == 1:
+			style = style.Bold(true)
+		case c == 2:
+			style = style.Dim(true)
+		case c == 4:
+			style = style.Underline(true)
+		case c == 5 || c == 6:
+			style = style.Blink(true)
+		case c == 7:
+			style = style.Reverse(true)
+		case c == 22:
+			style = style.Bold(false).Dim(false)
+		case c == 24:
+			style = style.Underline(false)
+		case c == 25:
+			style = style.Blink(false)
+		case c == 27:
+			style = style.Reverse(false)
+		case c >= 30 && c <= 37:
+			style = style.Foreground(tcell.Color(c - 30))
+		case c == 38:
+			color, n := extendedColor(codes[i+1:])
+			if color != tcell.ColorDefault {
+				style = style.Foreground(color)
+			}
+			i += n
+		case c == 39:
+			style = style.Foreground(basefg)
+		case c >= 40 && c <= 47:
+			style = style.Background(tcell.Color(c - 40))
+		case c == 48:
+			color, n := extendedColor(codes[i+1:])
+			if color != tcell.ColorDefault {
+				style = style.Background(color)
+			}
+			i += n
+		case c == 49:
+			style = style.Background(basebg)
+		case c >= 90 && c <= 97:
+			style = style.Foreground(tcell.Color(c - 90 + 8))
+		case c >= 100 && c <= 107:
+			style = style.Background(tcell.Color(c - 100 + 8))
+		}
+	}
+	return style
+}
+
+// extendedColor parses arguments of the 256-color (5;n) or the truecolor
+// (2;r;g;b) sequence.  It returns the color and the count of the consumed
+// arguments.
+func extendedColor(args []int) (tcell.Color, int) {
+	if len(args) >= 2 && args[0] == 5 {
+		return tcell.Color(args[1] & 0xff), 2
+	}
+	if len(args) >= 4 && args[0] == 2 {
+		return tcell.NewRGBColor(int32(args[1]), int32(args[2]), int32(args[3])), 4
+	}
+	return tcell.ColorDefault, len(args)
+}
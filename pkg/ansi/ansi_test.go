@@ -0,0 +1,87 @@
+package ansi
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestParse(t *testing.T) {
+	base := tcell.StyleDefault
+	red := base.Foreground(tcell.ColorMaroon)
+
+	cases := []struct {
+		input  string
+		text   string
+		styles []tcell.Style
+	}{
+		{
+			input:  "abc",
+			text:   "abc",
+			styles: []tcell.Style{base, base, base},
+		},
+		{
+			input:  "a\x1b[31mb\x1b[0mc",
+			text:   "abc",
+			styles: []tcell.Style{base, red, base},
+		},
+		{
+			input:  "\x1b[1;31mx\x1b[22my\x1b[39mz",
+			text:   "xyz",
+			styles: []tcell.Style{red.Bold(true), red, base},
+		},
+		{
+			input:  "\x1b[38;5;196mx\x1b[48;5;21my\x1b[m",
+			text:   "xy",
+			styles: []tcell.Style{base.Foreground(tcell.Color(196)), base.Foreground(tcell.Color(196)).Background(tcell.Color(21))},
+		},
+		{
+			input:  "\x1b[38;2;10;20;30mx",
+			text:   "x",
+			styles: []tcell.Style{base.Foreground(tcell.NewRGBColor(10, 20, 30))},
+		},
+		{
+			input:  "\x1b[2K\x1b]0;title\x07日本\x1b[91m語",
+			text:   "日本語",
+			styles: []tcell.Style{base, base, base.Foreground(tcell.ColorRed)},
+		},
+		{
+			input:  "broken\x1b[31",
+			text:   "broken",
+			styles: []tcell.Style{base, base, base, base, base, base},
+		},
+	}
+
+	for _, c := range cases {
+		text, styles := Parse(c.input, base)
+		if string(text) != c.text {
+			t.Errorf("Parse(%q): text %q != %q", c.input, string(text), c.text)
+			continue
+		}
+		if len(styles) != len(c.styles) {
+			t.Errorf("Parse(%q): len(styles) %d != %d", c.input, len(styles), len(c.styles))
+			continue
+		}
+		for i := range styles {
+			if styles[i] != c.styles[i] {
+				t.Errorf("Parse(%q): styles[%d] %v != %v", c.input, i, styles[i], c.styles[i])
+			}
+		}
+	}
+}
+
+func TestStrip(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"plain text", "plain text"},
+		{"\x1b[1;32mINFO\x1b[0m started", "INFO started"},
+		{"\x1b[38;2;255;0;0mred\x1b[m", "red"},
+	}
+	for _, c := range cases {
+		if s := Strip(c.input); s != c.expected {
+			t.Errorf("Strip(%q) = %q, want %q", c.input, s, c.expected)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package clipboard
+
+import (
+	"encoding/base64"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Clipboard copies text to the system clipboard.  The text is copied by the
+// OSC 52 escape sequence written to the terminal, which works over SSH
+// without clipboard tools.  The text is also piped to the command if the
+// command is set, for terminals not supporting OSC 52.
+type Clipboard struct {
+	command string
+	tty     io.Writer
+	tmux    bool
+}
+
+// New returns a new Clipboard which writes escape sequences to tty.  The
+// command is a shell command to copy text from the stdin, such as "pbcopy" or
+// "xclip -selection clipboard".
+func New(tty io.Writer, command string) *Clipboard {
+	return &Clipboard{
+		command: command,
+		tty:     tty,
+		tmux:    len(os.Getenv("TMUX")) > 0,
+	}
+}
+
+// Copy copies the text to the clipboard by OSC 52, and by the command if it
+// is set.  The error on writing to the terminal is ignored if the command
+// succeeds.
+func (c *Clipboard) Copy(text string) error {
+	_, err := io.WriteString(c.tty, OSC52(text, c.tmux))
+	if len(c.command) == 0 {
+		return errors.Wrap(err, "failed to write to terminal")
+	}
+
+	cmd := exec.Command("sh", "-c", c.command)
+	cmd.Stdin = strings.NewReader(text)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.Errorf("%s: %v: %s", c.command, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// OSC52 returns the escape sequence to copy the text into the clipboard.  The
+// sequence is wrapped by DCS passthrough if tmux is true.
+func OSC52(text string, tmux bool) string {
+	seq := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\a"
+	if tmux {
+		return "\x1bPtmux;\x1b" + seq + "\x1b\\"
+	}
+	return seq
+}
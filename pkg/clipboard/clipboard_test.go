@@ -0,0 +1,61 @@
+package clipboard
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOSC52(t *testing.T) {
+	cases := []struct {
+		text     string
+		tmux     bool
+		expected string
+	}{
+		{"hello", false, "\x1b]52;c;aGVsbG8=\a"},
+		{"hello", true, "\x1bPtmux;\x1b\x1b]52;c;aGVsbG8=\a\x1b\\"},
+		{"", false, "\x1b]52;c;\a"},
+	}
+	for _, c := range cases {
+		if s := OSC52(c.text, c.tmux); s != c.expected {
+			t.Errorf("OSC52(%q, %v) = %q, want %q", c.text, c.tmux, s, c.expected)
+		}
+	}
+}
+
+func TestClipboard_Copy(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Clipboard{tty: &buf}
+	if err := c.Copy("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != OSC52("hello", false) {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+
+	// the command copies the text in addition to OSC 52
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	out := filepath.Join(dir, "copied")
+	buf.Reset()
+	c = &Clipboard{tty: &buf, command: "cat > " + out}
+	if err := c.Copy("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != OSC52("hello", false) {
+		t.Errorf("unexpected output: %q", buf.String())
+	}
+	if b, err := ioutil.ReadFile(out); err != nil || string(b) != "hello" {
+		t.Errorf("unexpected copied text: %q, %v", b, err)
+	}
+
+	c = &Clipboard{tty: &buf, command: "exit 1"}
+	if err := c.Copy("hello"); err == nil {
+		t.Error("Copy should fail if the command fails")
+	}
+}
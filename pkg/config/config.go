@@ -0,0 +1,94 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/level"
+	"github.com/ueokande/logbook/pkg/widgets"
+	"sigs.k8s.io/yaml"
+)
+
+// Config is a config file of logbook
+type Config struct {
+	// Defaults is the default values of the command-line flags by their
+	// names, such as "namespace".  The flags given in the command-line take
+	// precedence.
+	Defaults map[string]interface{} `json:"defaults"`
+
+	// Keybindings is the actions bound to the keys in the modes, such as
+	// "normal: {ctrl-e: scroll-down, g: none, gg: scroll-top}"
+	Keybindings map[string]map[string]string `json:"keybindings"`
+
+	// Colors is the styles by the names of the elements, such as
+	// "statusbar.mode.normal: black on green bold"
+	Colors map[string]string `json:"colors"`
+
+	// Pager is the initial behavior of the pager
+	Pager Pager `json:"pager"`
+}
+
+// Pager is the initial behavior of the pager.  The zero value is the same as
+// the behavior without the config file.
+type Pager struct {
+	// Wrap enables wrapping long lines
+	Wrap bool `json:"wrap"`
+
+	// LineNumbers shows line numbers and timestamps in the gutter
+	LineNumbers bool `json:"line-numbers"`
+
+	// Timestamps is the format of the timestamps in the gutter, one of
+	// "local", "utc" or "relative"
+	Timestamps string `json:"timestamps"`
+
+	// Follow enables follow mode on selecting a container
+	Follow bool `json:"follow"`
+
+	// MinLevel is the minimum level of the lines shown, such as "warn"
+	MinLevel string `json:"min-level"`
+}
+
+// DefaultPath returns the path of the config file in $XDG_CONFIG_HOME, or
+// ~/.config if it is not set
+func DefaultPath() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if len(dir) == 0 {
+		home := os.Getenv("HOME")
+		if len(home) == 0 {
+			home = os.Getenv("USERPROFILE") // windows
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "logbook", "config.yaml")
+}
+
+// Load loads the config file at path.  Unknown fields and invalid values of
+// the pager are reported as errors.
+func Load(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Config
+	if err := yaml.UnmarshalStrict(b, &c); err != nil {
+		return nil, errors.Wrapf(err, "invalid config file %s", path)
+	}
+	if err := c.Pager.validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid config file %s", path)
+	}
+	return &c, nil
+}
+
+func (p *Pager) validate() error {
+	if len(p.Timestamps) > 0 {
+		if _, err := widgets.ParseTimestampFormat(p.Timestamps); err != nil {
+			return errors.Wrap(err, "pager.timestamps")
+		}
+	}
+	if len(p.MinLevel) > 0 && level.Parse(p.MinLevel) == level.Unknown {
+		return errors.Errorf("pager.min-level: unknown level %q", p.MinLevel)
+	}
+	return nil
+}
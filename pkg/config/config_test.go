@@ -0,0 +1,100 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.yaml")
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+defaults:
+  namespace: kube-system
+  json: true
+  json-time-fields: [ts, time]
+keybindings:
+  normal:
+    ctrl-e: scroll-down
+    g: none
+    gg: scroll-top
+colors:
+  pod.running: white on green bold
+pager:
+  wrap: true
+  line-numbers: true
+  timestamps: utc
+  min-level: warn
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Config{
+		Defaults: map[string]interface{}{
+			"namespace":        "kube-system",
+			"json":             true,
+			"json-time-fields": []interface{}{"ts", "time"},
+		},
+		Keybindings: map[string]map[string]string{
+			"normal": {"ctrl-e": "scroll-down", "g": "none", "gg": "scroll-top"},
+		},
+		Colors: map[string]string{"pod.running": "white on green bold"},
+		Pager:  Pager{Wrap: true, LineNumbers: true, Timestamps: "utc", MinLevel: "warn"},
+	}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("unexpected config: %#v", c)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	cases := []struct {
+		content string
+		message string
+	}{
+		{"pagr:\n  wrap: true\n", `unknown field "pagr"`},
+		{"pager:\n  wrap: yes please\n", "invalid config file"},
+		{"pager:\n  timestamps: gmt\n", `pager.timestamps: unknown timestamp format "gmt"`},
+		{"pager:\n  min-level: loud\n", `pager.min-level: unknown level "loud"`},
+	}
+	for _, c := range cases {
+		path := writeConfig(t, c.content)
+		_, err := Load(path)
+		os.RemoveAll(filepath.Dir(path))
+		if err == nil || !strings.Contains(err.Error(), c.message) || !strings.Contains(err.Error(), path) {
+			t.Errorf("unexpected error for %q: %v", c.content, err)
+		}
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	defer func(v string) { os.Setenv("XDG_CONFIG_HOME", v) }(os.Getenv("XDG_CONFIG_HOME"))
+	defer func(v string) { os.Setenv("HOME", v) }(os.Getenv("HOME"))
+
+	os.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if path := DefaultPath(); path != filepath.Join("/xdg", "logbook", "config.yaml") {
+		t.Errorf("unexpected path: %s", path)
+	}
+	os.Setenv("XDG_CONFIG_HOME", "")
+	os.Setenv("HOME", "/home/alice")
+	if path := DefaultPath(); path != filepath.Join("/home/alice", ".config", "logbook", "config.yaml") {
+		t.Errorf("unexpected path: %s", path)
+	}
+}
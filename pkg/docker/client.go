@@ -0,0 +1,134 @@
+package docker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHost is the address of Docker Engine used if DOCKER_HOST is not set
+const DefaultHost = "unix:///var/run/docker.sock"
+
+// The labels of the containers created by docker compose
+const (
+	LabelComposeProject = "com.docker.compose.project"
+	LabelComposeService = "com.docker.compose.service"
+)
+
+// Client is a client of Docker Engine API over the unix socket
+type Client struct {
+	http *http.Client
+}
+
+// Container is a container returned by Docker Engine API
+type Container struct {
+	ID     string `json:"Id"`
+	Names  []string
+	Image  string
+	State  string
+	Status string
+	Labels map[string]string
+}
+
+// Name returns the name of the container without the leading slash
+func (c *Container) Name() string {
+	if len(c.Names) == 0 {
+		return c.ID
+	}
+	return strings.TrimPrefix(c.Names[0], "/")
+}
+
+// NewClient returns a new Client connecting to the host, such as
+// "unix:///var/run/docker.sock".  The host is taken from DOCKER_HOST or
+// DefaultHost if it is empty.
+func NewClient(host string) (*Client, error) {
+	if len(host) == 0 {
+		host = os.Getenv("DOCKER_HOST")
+	}
+	if len(host) == 0 {
+		host = DefaultHost
+	}
+	if !strings.HasPrefix(host, "unix://") {
+		return nil, errors.Errorf("unsupported docker host: %s", host)
+	}
+	socket := strings.TrimPrefix(host, "unix://")
+
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}
+	return &Client{
+		http: &http.Client{Transport: transport},
+	}, nil
+}
+
+// ListContainers lists all containers including stopped ones
+func (c *Client) ListContainers(ctx context.Context) ([]Container, error) {
+	var containers []Container
+	err := c.getJSON(ctx, "/containers/json", url.Values{"all": {"1"}}, &containers)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list containers")
+	}
+	return containers, nil
+}
+
+// tty returns true if the container is attached to a TTY.  The logs of the
+// container are not multiplexed in that case.
+func (c *Client) tty(ctx context.Context, id string) (bool, error) {
+	var container struct {
+		Config struct {
+			Tty bool
+		}
+	}
+	err := c.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &container)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to inspect container")
+	}
+	return container.Config.Tty, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, path string, query url.Values, v interface{}) error {
+	body, err := c.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return json.NewDecoder(body).Decode(v)
+}
+
+// get requests GET to the path with the query, and returns the body of the
+// response.  It returns an error if the status is not 200.
+func (c *Client) get(ctx context.Context, path string, query url.Values) (io.ReadCloser, error) {
+	u := url.URL{Scheme: "http", Host: "docker", Path: path, RawQuery: query.Encode()}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var msg struct {
+			Message string `json:"message"`
+		}
+		b, _ := ioutil.ReadAll(resp.Body)
+		if json.Unmarshal(b, &msg) != nil || len(msg.Message) == 0 {
+			msg.Message = strings.TrimSpace(string(b))
+		}
+		return nil, fmt.Errorf("%s: %s", resp.Status, msg.Message)
+	}
+	return resp.Body, nil
+}
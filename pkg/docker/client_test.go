@@ -0,0 +1,121 @@
+package docker
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// frame returns the multiplexed frame of the payload
+func frame(stream byte, payload string) []byte {
+	header := make([]byte, 8)
+	header[0] = stream
+	binary.BigEndian.PutUint32(header[4:], uint32(len(payload)))
+	return append(header, payload...)
+}
+
+// newTestServer starts a server speaking Docker Engine API on the unix
+// socket, and returns the client connecting to it
+func newTestServer(t *testing.T, handler http.Handler) (*Client, func()) {
+	dir, err := ioutil.TempDir("", "logbook-docker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "docker.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = l
+	server.Start()
+
+	client, err := NewClient("unix://" + socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client, func() {
+		server.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/containers/json", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("all") != "1" {
+			t.Errorf("all containers are not requested: %s", r.URL)
+		}
+		fmt.Fprint(w, `[{"Id":"abc","Names":["/shop-web-1"],"Image":"nginx","State":"running","Status":"Up 5 minutes","Labels":{"com.docker.compose.project":"shop","com.docker.compose.service":"web"}}]`)
+	})
+	mux.HandleFunc("/containers/abc/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Id":"abc","Config":{"Tty":false}}`)
+	})
+	mux.HandleFunc("/containers/abc/logs", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("follow") != "1" || q.Get("timestamps") != "1" || q.Get("since") != "" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		w.Write(frame(streamStdout, "2019-07-01T12:00:00Z hello "))
+		w.Write(frame(streamStderr, "2019-07-01T12:00:01Z oops\n"))
+		// the rest of the split line has its own timestamp
+		w.Write(frame(streamStdout, "2019-07-01T12:00:00.5Z world\n"))
+		w.Write(frame(streamStdout, "2019-07-01T12:00:02Z bye"))
+	})
+	mux.HandleFunc("/containers/tty/json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"Id":"tty","Config":{"Tty":true}}`)
+	})
+	mux.HandleFunc("/containers/tty/logs", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "2019-07-01T12:00:00Z raw\r\n")
+	})
+	client, cleanup := newTestServer(t, mux)
+	defer cleanup()
+
+	ctx := context.Background()
+	containers, err := client.ListContainers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 || containers[0].Name() != "shop-web-1" || containers[0].Labels[LabelComposeService] != "web" {
+		t.Errorf("unexpected containers: %+v", containers)
+	}
+
+	cases := []struct {
+		id       string
+		expected []string
+	}{
+		{"abc", []string{"stderr 12:00:01 oops", "stdout 12:00:00 hello world", "stdout 12:00:02 bye"}},
+		{"tty", []string{"stdout 12:00:00 raw"}},
+	}
+	for _, c := range cases {
+		ch, err := client.Logs(ctx, c.id, LogOptions{Follow: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for line := range ch {
+			stream := "stdout"
+			if line.Stderr {
+				stream = "stderr"
+			}
+			lines = append(lines, strings.Join([]string{stream, line.Time.Format("15:04:05"), line.Text}, " "))
+		}
+		if !reflect.DeepEqual(lines, c.expected) {
+			t.Errorf("%s: expected %q, actual %q", c.id, c.expected, lines)
+		}
+	}
+
+	_, err = client.Logs(ctx, "missing", LogOptions{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,154 @@
+package docker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/timestamp"
+)
+
+// The stream types in the header of the multiplexed logs
+const (
+	streamStdout = 1
+	streamStderr = 2
+)
+
+// LogOptions is options to stream container's logs
+type LogOptions struct {
+	// Follow streams new logs until the container stops
+	Follow bool
+
+	// Since is the time to start showing logs from.  All logs are returned
+	// if it is zero.
+	Since time.Time
+}
+
+// LogLine is a line of the container's log
+type LogLine struct {
+	// Time is the timestamp of the line provided by Docker Engine
+	Time time.Time
+
+	// Text is the content of the line without the timestamp
+	Text string
+
+	// Stderr is true if the line is written to stderr
+	Stderr bool
+}
+
+// Logs streams stdout and stderr of the container by the id.  It returns a
+// channel to subscribe log lines, which is closed when logs end or ctx is
+// done.
+func (c *Client) Logs(ctx context.Context, id string, opts LogOptions) (<-chan LogLine, error) {
+	tty, err := c.tty(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"stdout":     {"1"},
+		"stderr":     {"1"},
+		"timestamps": {"1"},
+	}
+	if opts.Follow {
+		query.Set("follow", "1")
+	}
+	if !opts.Since.IsZero() {
+		query.Set("since", fmt.Sprintf("%d.%09d", opts.Since.Unix(), opts.Since.Nanosecond()))
+	}
+	body, err := c.get(ctx, "/containers/"+url.PathEscape(id)+"/logs", query)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan LogLine)
+	send := func(line string, stderr bool) bool {
+		l := LogLine{Stderr: stderr}
+		l.Time, l.Text = timestamp.Split(line)
+		select {
+		case ch <- l:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(ch)
+		defer body.Close()
+		if tty {
+			// the logs of the container with TTY are not multiplexed
+			s := bufio.NewScanner(body)
+			for s.Scan() {
+				if !send(strings.TrimRight(s.Text(), "\r"), false) {
+					return
+				}
+			}
+			return
+		}
+		demux(body, send)
+	}()
+	return ch, nil
+}
+
+// demux reads the multiplexed stdout and stderr from r, and sends their
+// lines to send.  Each frame has an 8-byte header of the stream type and the
+// size of the payload.  A line can be split into multiple frames.
+func demux(r io.Reader, send func(line string, stderr bool) bool) error {
+	var stdout, stderr bytes.Buffer
+	flush := func(buf *bytes.Buffer, isStderr bool, all bool) bool {
+		for {
+			i := bytes.IndexByte(buf.Bytes(), '\n')
+			if i == -1 {
+				break
+			}
+			line := string(buf.Next(i + 1))
+			if !send(strings.TrimRight(line, "\r\n"), isStderr) {
+				return false
+			}
+		}
+		if all && buf.Len() > 0 {
+			line := buf.String()
+			buf.Reset()
+			return send(line, isStderr)
+		}
+		return true
+	}
+
+	br := bufio.NewReader(r)
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(br, header); err != nil {
+			flush(&stdout, false, true)
+			flush(&stderr, true, true)
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		size := int64(binary.BigEndian.Uint32(header[4:]))
+
+		buf, isStderr := &stdout, false
+		if header[0] == streamStderr {
+			buf, isStderr = &stderr, true
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return err
+		}
+		if buf.Len() > 0 {
+			// each frame of the split line has its own timestamp
+			_, text := timestamp.Split(string(payload))
+			payload = []byte(text)
+		}
+		buf.Write(payload)
+		if !flush(buf, isStderr, false) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package jsonlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fields is a set of the field names to find the time, the level, and the
+// message in the JSON log.  The first name found in the object is used.
+type Fields struct {
+	Time    []string
+	Level   []string
+	Message []string
+}
+
+// DefaultFields is the field names used by common logging libraries.
+var DefaultFields = Fields{
+	Time:    []string{"ts", "time", "@timestamp"},
+	Level:   []string{"level", "severity"},
+	Message: []string{"msg", "message"},
+}
+
+// Attr is a key-value pair in the JSON log except for the time, the level,
+// and the message.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// Entry is a parsed JSON log
+type Entry struct {
+	Time    string
+	Level   string
+	Message string
+	Attrs   []Attr
+}
+
+// Parse parses a line as a JSON object.  It returns false if the line is not
+// a JSON object.  Attributes in the entry keep the order in the object.
+func Parse(line string, fields Fields) (*Entry, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") || !strings.HasSuffix(line, "}") {
+		return nil, false
+	}
+
+	d := json.NewDecoder(strings.NewReader(line))
+	d.UseNumber()
+	if t, err := d.Token(); err != nil || t != json.Delim('{') {
+		return nil, false
+	}
+
+	var keys []string
+	values := make(map[string]json.RawMessage)
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, false
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, false
+		}
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return nil, false
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = raw
+	}
+	if t, err := d.Token(); err != nil || t != json.Delim('}') {
+		return nil, false
+	}
+
+	e := &Entry{}
+	used := make(map[string]bool)
+	if key, ok := lookup(values, fields.Time); ok {
+		e.Time = formatTime(values[key])
+		used[key] = true
+	}
+	if key, ok := lookup(values, fields.Level); ok {
+		e.Level = formatValue(values[key])
+		used[key] = true
+	}
+	if key, ok := lookup(values, fields.Message); ok {
+		e.Message = formatValue(values[key])
+		used[key] = true
+	}
+	for _, key := range keys {
+		if used[key] {
+			continue
+		}
+		e.Attrs = append(e.Attrs, Attr{Key: key, Value: formatValue(values[key])})
+	}
+	return e, true
+}
+
+// String returns the entry in the format "time level msg key=value ...".
+// Empty fields are omitted.
+func (e *Entry) String() string {
+	var parts []string
+	for _, s := range []string{e.Time, e.Level, e.Message} {
+		if len(s) > 0 {
+			parts = append(parts, s)
+		}
+	}
+	for _, a := range e.Attrs {
+		parts = append(parts, a.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// String returns the attribute in the format "key=value".  The value is
+// quoted if it contains spaces.
+func (a Attr) String() string {
+	return a.Key + "=" + a.QuotedValue()
+}
+
+// QuotedValue returns the value, which is quoted if it contains spaces.
+func (a Attr) QuotedValue() string {
+	if len(a.Value) == 0 || strings.ContainsAny(a.Value, " \t\n\"=") {
+		return strconv.Quote(a.Value)
+	}
+	return a.Value
+}
+
+// Indent returns pretty-printed JSON of the line.
+func Indent(line string) (string, error) {
+	var buf bytes.Buffer
+	err := json.Indent(&buf, []byte(strings.TrimSpace(line)), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func lookup(values map[string]json.RawMessage, names []string) (string, bool) {
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func formatValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// formatTime formats the time field.  The number is treated as UNIX time in
+// seconds.
+func formatTime(raw json.RawMessage) string {
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err != nil {
+		return formatValue(raw)
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return string(n)
+	}
+	sec := int64(f)
+	nsec := int64((f - float64(sec)) * 1e9)
+	return time.Unix(sec, nsec).UTC().Format("2006-01-02T15:04:05.000Z07:00")
+}
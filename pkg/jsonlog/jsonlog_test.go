@@ -0,0 +1,97 @@
+package jsonlog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected *Entry
+	}{
+		{
+			line: `{"level":"info","ts":1561939200.5,"msg":"started","port":8080,"tags":["a","b"]}`,
+			expected: &Entry{
+				Time:    "2019-07-01T00:00:00.500Z",
+				Level:   "info",
+				Message: "started",
+				Attrs:   []Attr{{"port", "8080"}, {"tags", `["a","b"]`}},
+			},
+		},
+		{
+			line: `{"@timestamp":"2019-07-01T00:00:00Z","severity":"ERROR","message":"failed to connect","error":"connection refused"}`,
+			expected: &Entry{
+				Time:    "2019-07-01T00:00:00Z",
+				Level:   "ERROR",
+				Message: "failed to connect",
+				Attrs:   []Attr{{"error", "connection refused"}},
+			},
+		},
+		{
+			line:     `{"user":{"id":1}}`,
+			expected: &Entry{Attrs: []Attr{{"user", `{"id":1}`}}},
+		},
+		{line: `plain text`},
+		{line: `{"broken":`},
+		{line: `["array"]`},
+	}
+
+	for _, c := range cases {
+		e, ok := Parse(c.line, DefaultFields)
+		if c.expected == nil {
+			if ok {
+				t.Errorf("Parse(%q) should fail: %#v", c.line, e)
+			}
+			continue
+		}
+		if !ok {
+			t.Errorf("Parse(%q) fails", c.line)
+			continue
+		}
+		if !reflect.DeepEqual(e, c.expected) {
+			t.Errorf("Parse(%q) = %#v, want %#v", c.line, e, c.expected)
+		}
+	}
+}
+
+func TestParseCustomFields(t *testing.T) {
+	fields := Fields{Time: []string{"t"}, Level: []string{"lvl"}, Message: []string{"text"}}
+	e, ok := Parse(`{"text":"hello","lvl":"warn","t":"now","msg":"other"}`, fields)
+	if !ok {
+		t.Fatal("parse failed")
+	}
+	expected := &Entry{Time: "now", Level: "warn", Message: "hello", Attrs: []Attr{{"msg", "other"}}}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("%#v != %#v", e, expected)
+	}
+}
+
+func TestEntryString(t *testing.T) {
+	e := &Entry{
+		Time:    "12:00:00",
+		Level:   "info",
+		Message: "request done",
+		Attrs:   []Attr{{"path", "/index.html"}, {"agent", "curl 7.0"}, {"empty", ""}},
+	}
+	expected := `12:00:00 info request done path=/index.html agent="curl 7.0" empty=""`
+	if s := e.String(); s != expected {
+		t.Errorf("%q != %q", s, expected)
+	}
+}
+
+func TestIndent(t *testing.T) {
+	s, err := Indent(`{"a":1,"b":[true]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  \"a\": 1,\n  \"b\": [\n    true\n  ]\n}"
+	if s != expected {
+		t.Errorf("%q != %q", s, expected)
+	}
+
+	_, err = Indent(`not a json`)
+	if err == nil {
+		t.Error("expected error")
+	}
+}
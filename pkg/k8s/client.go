@@ -9,7 +9,7 @@ import (
 
 // Client is a wrapper for a Kubernetes client
 type Client struct {
-	clientset *kubernetes.Clientset
+	clientset kubernetes.Interface
 }
 
 // NewClient loads Kubernetes configuration by the kubeconfig and returns new
@@ -25,9 +25,15 @@ func NewClient(kubeconfig string) (*Client, error) {
 		return nil, err
 	}
 
+	return NewClientForClientset(clientset), nil
+}
+
+// NewClientForClientset returns new Client with the clientset, such as a fake
+// clientset in tests
+func NewClientForClientset(clientset kubernetes.Interface) *Client {
 	return &Client{
 		clientset: clientset,
-	}, nil
+	}
 }
 
 // LoadCurrentContext loads a context in KUBECONFIG and returns it
@@ -0,0 +1,114 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// EventOptions is options to watch events
+type EventOptions struct {
+	// Kind and Name select events of the involved object, such as the Pod
+	// named "nginx".  All events in the namespace are selected if Name is
+	// empty.
+	Kind string
+	Name string
+}
+
+func (opts EventOptions) fieldSelector() string {
+	if len(opts.Name) == 0 {
+		return ""
+	}
+	return fields.Set{
+		"involvedObject.kind": opts.Kind,
+		"involvedObject.name": opts.Name,
+	}.AsSelector().String()
+}
+
+func (opts EventOptions) matches(ev *corev1.Event) bool {
+	if len(opts.Name) == 0 {
+		return true
+	}
+	return ev.InvolvedObject.Kind == opts.Kind && ev.InvolvedObject.Name == opts.Name
+}
+
+// EventTime returns the time when the event occurred last
+func EventTime(ev *corev1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	}
+	return ev.FirstTimestamp.Time
+}
+
+// WatchEvents watches events from Kubernetes API in namespace.  The current
+// events are sent first in order of the time, and then the events added or
+// updated are sent.  The channel is closed when ctx is done.
+func (c *Client) WatchEvents(ctx context.Context, namespace string, opts EventOptions) (<-chan *corev1.Event, error) {
+	listOpts := metav1.ListOptions{FieldSelector: opts.fieldSelector()}
+	list, err := c.clientset.CoreV1().Events(namespace).List(listOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list events")
+	}
+	listOpts.ResourceVersion = list.ResourceVersion
+	r, err := c.clientset.CoreV1().Events(namespace).Watch(listOpts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch events")
+	}
+
+	current := list.Items
+	sort.SliceStable(current, func(i, j int) bool {
+		return EventTime(&current[i]).Before(EventTime(&current[j]))
+	})
+
+	ch := make(chan *corev1.Event)
+	go func() {
+		defer close(ch)
+		defer r.Stop()
+
+		send := func(ev *corev1.Event) bool {
+			// the field selector may be ignored by the server
+			if !opts.matches(ev) {
+				return true
+			}
+			select {
+			case ch <- ev:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for i := range current {
+			if !send(&current[i]) {
+				return
+			}
+		}
+		for {
+			select {
+			case ev, ok := <-r.ResultChan():
+				if !ok {
+					return
+				}
+				// deleted events are expired, and not to be removed from logs
+				e, isEvent := ev.Object.(*corev1.Event)
+				if !isEvent || ev.Type == watch.Deleted {
+					continue
+				}
+				if !send(e) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
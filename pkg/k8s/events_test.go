@@ -0,0 +1,88 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func podEvent(name, pod, reason string, last time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default"},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod},
+		Reason:         reason,
+		LastTimestamp:  metav1.Time{Time: last},
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	clientset := fake.NewSimpleClientset(
+		podEvent("nginx.2", "nginx", "Started", now.Add(2*time.Second)),
+		podEvent("nginx.1", "nginx", "Pulled", now.Add(time.Second)),
+		podEvent("redis.1", "redis", "Pulled", now),
+	)
+	client := NewClientForClientset(clientset)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.WatchEvents(ctx, "default", EventOptions{Kind: "Pod", Name: "nginx"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the current events are sent in order of the time
+	for _, reason := range []string{"Pulled", "Started"} {
+		if ev := <-events; ev.InvolvedObject.Name != "nginx" || ev.Reason != reason {
+			t.Errorf("unexpected event: %s %s, want nginx %s", ev.InvolvedObject.Name, ev.Reason, reason)
+		}
+	}
+
+	for _, ev := range []*corev1.Event{
+		podEvent("redis.2", "redis", "Started", now.Add(3*time.Second)),
+		podEvent("nginx.3", "nginx", "Killing", now.Add(4*time.Second)),
+	} {
+		if _, err := clientset.CoreV1().Events("default").Create(ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ev := <-events; ev.InvolvedObject.Name != "nginx" || ev.Reason != "Killing" {
+		t.Errorf("unexpected event: %s %s, want nginx Killing", ev.InvolvedObject.Name, ev.Reason)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestEventTime(t *testing.T) {
+	first := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	last := first.Add(time.Minute)
+
+	cases := []struct {
+		event    corev1.Event
+		expected time.Time
+	}{
+		{
+			event:    corev1.Event{FirstTimestamp: metav1.Time{Time: first}, LastTimestamp: metav1.Time{Time: last}},
+			expected: last,
+		},
+		{
+			event:    corev1.Event{EventTime: metav1.MicroTime{Time: last}},
+			expected: last,
+		},
+		{
+			event:    corev1.Event{FirstTimestamp: metav1.Time{Time: first}},
+			expected: first,
+		},
+	}
+	for _, c := range cases {
+		if actual := EventTime(&c.event); !actual.Equal(c.expected) {
+			t.Errorf("unexpected time: %v, want %v", actual, c.expected)
+		}
+	}
+}
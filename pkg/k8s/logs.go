@@ -3,18 +3,55 @@ package k8s
 import (
 	"bufio"
 	"context"
+	"time"
 
+	"github.com/ueokande/logbook/pkg/timestamp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// LogOptions is options to watch container's logs
+type LogOptions struct {
+	// Timestamps requests the timestamp of each line to Kubernetes
+	Timestamps bool
+
+	// SinceTime is the time to start showing logs from.  The all logs are
+	// returned if it is zero.
+	SinceTime time.Time
+}
+
+// LogLine is a line of the container's log
+type LogLine struct {
+	// Time is the timestamp of the line provided by Kubernetes.  It is zero
+	// if the timestamps are not requested.
+	Time time.Time
+
+	// Text is the content of the line without the timestamp
+	Text string
+}
+
 // WatchLogs watches container's logs of pod in namespace.  It returns channels
 // to subscribe log lines.
-func (c *Client) WatchLogs(ctx context.Context, namespace, pod, container string) (<-chan string, error) {
-	opts := &corev1.PodLogOptions{
-		Container: container,
-		Follow:    true,
+func (c *Client) WatchLogs(ctx context.Context, namespace, pod, container string, opts LogOptions) (<-chan LogLine, error) {
+	return c.streamLogs(ctx, namespace, pod, container, opts, true)
+}
+
+// ReadLogs reads container's logs of pod in namespace without following new
+// logs.  The channel is closed after all lines are sent.
+func (c *Client) ReadLogs(ctx context.Context, namespace, pod, container string, opts LogOptions) (<-chan LogLine, error) {
+	return c.streamLogs(ctx, namespace, pod, container, opts, false)
+}
+
+func (c *Client) streamLogs(ctx context.Context, namespace, pod, container string, opts LogOptions, follow bool) (<-chan LogLine, error) {
+	podOpts := &corev1.PodLogOptions{
+		Container:  container,
+		Follow:     follow,
+		Timestamps: opts.Timestamps,
+	}
+	if !opts.SinceTime.IsZero() {
+		podOpts.SinceTime = &metav1.Time{Time: opts.SinceTime}
 	}
-	req := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, opts)
+	req := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, podOpts)
 	req.Context(ctx)
 	r, err := req.Stream()
 	if err != nil {
@@ -24,13 +61,22 @@ func (c *Client) WatchLogs(ctx context.Context, namespace, pod, container string
 	// TODO handle s.Err()
 	s := bufio.NewScanner(r)
 
-	ch := make(chan string)
+	ch := make(chan LogLine)
 	go func() {
+		defer r.Close()
+		defer close(ch)
+
 		for s.Scan() {
-			ch <- s.Text()
+			line := LogLine{Text: s.Text()}
+			if opts.Timestamps {
+				line.Time, line.Text = timestamp.Split(s.Text())
+			}
+			select {
+			case ch <- line:
+			case <-ctx.Done():
+				return
+			}
 		}
-		close(ch)
-		defer r.Close()
 	}()
 
 	return ch, nil
@@ -0,0 +1,423 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+// nodeLogPollInterval is the interval to poll logs of the node, because the
+// kubelet does not follow logs
+var nodeLogPollInterval = 2 * time.Second
+
+// nodeLogTailBytes is the size of the tail of the file read first
+const nodeLogTailBytes = 1 << 20
+
+// nodeLogTailLines is the count of the lines of the service read first
+const nodeLogTailLines = 1000
+
+// journalOutput is the output format of journalctl requested to the node log
+// query.  Its timestamps have the offset of the local time of the node.
+const journalOutput = "short-iso-precise"
+
+// journalTimeLayouts is the layouts of the timestamp in "short-iso-precise"
+// output of journalctl.  The offset has a colon in newer versions of systemd.
+var journalTimeLayouts = []string{
+	"2006-01-02T15:04:05.000000-0700",
+	"2006-01-02T15:04:05.000000Z07:00",
+}
+
+// journalShortTimeLayout is the layout of the timestamp in "short-precise"
+// output of journalctl, which is returned by the kubelet ignoring the output
+// format
+const journalShortTimeLayout = "Jan 02 15:04:05.000000"
+
+var (
+	hrefPattern         = regexp.MustCompile(`<a href="([^"]+)">`)
+	contentRangePattern = regexp.MustCompile(`^bytes (?:(\d+)-\d+|\*)/(\d+)$`)
+)
+
+// NodeEventType represents an event type of the node
+type NodeEventType int
+
+// The event type of the nodes
+const (
+	NodeAdded    NodeEventType = iota // The node is added
+	NodeModified                      // The node is updated
+	NodeDeleted                       // The node is deleted
+)
+
+// NodeEvent represents an event of the nodes in Kubernetes API
+type NodeEvent struct {
+	Type NodeEventType
+	Node *corev1.Node
+}
+
+// WatchNodes watches nodes from Kubernetes API.  It returns a channel to
+// subscribe nodes.  The channel is closed when ctx is done.
+func (c *Client) WatchNodes(ctx context.Context) (<-chan *NodeEvent, error) {
+	r, err := c.clientset.CoreV1().Nodes().Watch(metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to watch nodes")
+	}
+	ch := make(chan *NodeEvent)
+	go func() {
+		defer close(ch)
+		defer r.Stop()
+		for {
+			select {
+			case ev, ok := <-r.ResultChan():
+				if !ok {
+					return
+				}
+				node, ok := ev.Object.(*corev1.Node)
+				if !ok {
+					continue
+				}
+				var t NodeEventType
+				switch ev.Type {
+				case watch.Added:
+					t = NodeAdded
+				case watch.Modified:
+					t = NodeModified
+				case watch.Deleted:
+					t = NodeDeleted
+				default:
+					continue
+				}
+				select {
+				case ch <- &NodeEvent{Type: t, Node: node}:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// ListNodeLogFiles lists the log files in /var/log of the node via the
+// kubelet proxy.  Directories are not listed.
+func (c *Client) ListNodeLogFiles(ctx context.Context, node string) ([]string, error) {
+	resp, err := c.getNodeLogs(ctx, node, "", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list logs of %s", node)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to list logs of %s: %s", node, resp.Status)
+	}
+
+	var files []string
+	for _, m := range hrefPattern.FindAllStringSubmatch(string(body), -1) {
+		name, err := url.PathUnescape(m[1])
+		if err != nil || strings.HasSuffix(name, "/") || strings.Contains(name, "/") {
+			continue
+		}
+		files = append(files, name)
+	}
+	return files, nil
+}
+
+// WatchNodeLogFile watches the log file in /var/log of the node via the
+// kubelet proxy.  The tail of the file is sent first, and then lines appended
+// are sent by polling the file.  The file is read from the beginning again
+// if it is truncated or rotated.  The channel is closed when ctx is done.
+func (c *Client) WatchNodeLogFile(ctx context.Context, node, file string) (<-chan LogLine, error) {
+	f := &nodeLogFile{client: c, node: node, file: file}
+	lines, err := f.readTail(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := nodeLogPollInterval
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for {
+			for _, line := range lines {
+				select {
+				case ch <- LogLine{Text: line}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return
+			}
+			// retry on the next time on errors, such as the node is restarting
+			lines, _ = f.readNext(ctx)
+		}
+	}()
+	return ch, nil
+}
+
+// nodeLogFile is a state to follow the log file on the node by range
+// requests.  The offset is always next to a line break, so that the byte
+// before the offset is requested together to check the file is not replaced.
+type nodeLogFile struct {
+	client *Client
+	node   string
+	file   string
+	offset int64
+}
+
+// readTail reads the complete lines in the tail of the file
+func (f *nodeLogFile) readTail(ctx context.Context) ([]string, error) {
+	resp, body, err := f.get(ctx, fmt.Sprintf("bytes=-%d", nodeLogTailBytes))
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		// the file is empty
+		return nil, nil
+	case http.StatusPartialContent:
+		start, _ := parseContentRange(resp.Header.Get("Content-Range"))
+		f.offset = start
+		if start > 0 {
+			// drop the line cut in the middle
+			i := bytes.IndexByte(body, '\n')
+			f.offset += int64(i + 1)
+			body = body[i+1:]
+		}
+	case http.StatusOK:
+		f.offset = 0
+	default:
+		return nil, errors.Errorf("failed to get %s of %s: %s", f.file, f.node, resp.Status)
+	}
+	return f.consume(body), nil
+}
+
+// readNext reads the complete lines appended since the last read
+func (f *nodeLogFile) readNext(ctx context.Context) ([]string, error) {
+	if f.offset == 0 {
+		return f.readTail(ctx)
+	}
+	resp, body, err := f.get(ctx, fmt.Sprintf("bytes=%d-", f.offset-1))
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		if _, size := parseContentRange(resp.Header.Get("Content-Range")); size < f.offset {
+			// the file is truncated
+			f.offset = 0
+			return f.readTail(ctx)
+		}
+		return nil, nil
+	case http.StatusPartialContent:
+		if len(body) == 0 || body[0] != '\n' {
+			// the file is replaced by rotation
+			f.offset = 0
+			return f.readTail(ctx)
+		}
+		return f.consume(body[1:]), nil
+	case http.StatusOK:
+		// the range is not supported
+		if int64(len(body)) < f.offset {
+			f.offset = 0
+			return f.consume(body), nil
+		}
+		return f.consume(body[f.offset:]), nil
+	}
+	return nil, errors.Errorf("failed to get %s of %s: %s", f.file, f.node, resp.Status)
+}
+
+// consume returns the complete lines in body, and advances the offset to
+// the end of the last line
+func (f *nodeLogFile) consume(body []byte) []string {
+	i := bytes.LastIndexByte(body, '\n')
+	if i < 0 {
+		return nil
+	}
+	f.offset += int64(i + 1)
+	return strings.Split(string(body[:i]), "\n")
+}
+
+func (f *nodeLogFile) get(ctx context.Context, byteRange string) (*http.Response, []byte, error) {
+	resp, err := f.client.getNodeLogs(ctx, f.node, f.file, nil, http.Header{"Range": {byteRange}})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to get %s of %s", f.file, f.node)
+	}
+	return resp, body, nil
+}
+
+// parseContentRange returns the start and the size of the file in the
+// Content-Range header, such as "bytes 100-199/200" or "bytes */200"
+func parseContentRange(s string) (start, size int64) {
+	m := contentRangePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0
+	}
+	start, _ = strconv.ParseInt(m[1], 10, 64)
+	size, _ = strconv.ParseInt(m[2], 10, 64)
+	return start, size
+}
+
+// WatchNodeServiceLogs watches logs of the service on the node, such as
+// kubelet, by the node log query of the kubelet proxy.  The logs since the
+// time are sent first if since is not zero, otherwise the tail of the logs.
+// The new logs are sent by polling the query.  The channel is closed when ctx
+// is done.
+func (c *Client) WatchNodeServiceLogs(ctx context.Context, node, service string, since time.Time) (<-chan LogLine, error) {
+	queried := time.Now()
+	lines, err := c.queryNodeLogs(ctx, node, service, since)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := nodeLogPollInterval
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		last := since
+		for {
+			for _, line := range lines {
+				// the query returns lines in the second of the last line again
+				if !line.Time.IsZero() && !line.Time.After(last) {
+					continue
+				}
+				if !line.Time.IsZero() {
+					last = line.Time
+				}
+				select {
+				case ch <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return
+			}
+			next := last
+			if next.IsZero() {
+				next = queried
+			}
+			queried = time.Now()
+			// retry on the next time on errors, such as the node is restarting
+			lines, _ = c.queryNodeLogs(ctx, node, service, next)
+		}
+	}()
+	return ch, nil
+}
+
+// queryNodeLogs returns the logs of the service on the node since the time,
+// or the tail of the logs if since is zero
+func (c *Client) queryNodeLogs(ctx context.Context, node, service string, since time.Time) ([]LogLine, error) {
+	params := url.Values{"query": {service}, "output": {journalOutput}}
+	if since.IsZero() {
+		params.Set("tailLines", strconv.Itoa(nodeLogTailLines))
+	} else {
+		params.Set("sinceTime", since.UTC().Format(time.RFC3339))
+	}
+	resp, err := c.getNodeLogs(ctx, node, "", params, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query logs of %s on %s", service, node)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to query logs of %s on %s: %s: %s", service, node, resp.Status, strings.TrimSpace(string(body)))
+	}
+	// the kubelet not supporting the query returns the list of the files
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, errors.Errorf("node log query is not supported by %s", node)
+	}
+
+	var lines []LogLine
+	year := time.Now().UTC().Year()
+	for _, s := range strings.Split(strings.TrimRight(string(body), "\n"), "\n") {
+		// skip messages of journalctl, such as "-- No entries --"
+		if len(s) == 0 || strings.HasPrefix(s, "-- ") {
+			continue
+		}
+		lines = append(lines, parseJournalLine(s, year))
+	}
+	return lines, nil
+}
+
+// parseJournalLine parses a line of the journal in "short-iso-precise"
+// output, such as "2019-07-01T21:00:00.123456+0900 node kubelet[1]: message".
+// The line in "short-precise" output, such as "Jul 01 12:00:00.123456 node
+// kubelet[1]: message", is parsed as UTC in the year because neither the
+// offset nor the year is contained in the line.
+func parseJournalLine(s string, year int) LogLine {
+	if i := strings.IndexByte(s, ' '); i > 0 {
+		for _, layout := range journalTimeLayouts {
+			if t, err := time.Parse(layout, s[:i]); err == nil {
+				return LogLine{Time: t, Text: s[i+1:]}
+			}
+		}
+	}
+
+	if len(s) <= len(journalShortTimeLayout) {
+		return LogLine{Text: s}
+	}
+	t, err := time.Parse(journalShortTimeLayout, s[:len(journalShortTimeLayout)])
+	if err != nil {
+		return LogLine{Text: s}
+	}
+	t = t.AddDate(year-t.Year(), 0, 0)
+	return LogLine{Time: t, Text: strings.TrimPrefix(s[len(journalShortTimeLayout):], " ")}
+}
+
+// getNodeLogs sends a request to the path under /var/log of the node via the
+// kubelet proxy.  The response is returned without checking its status, so
+// that the headers of the range requests can be checked.
+func (c *Client) getNodeLogs(ctx context.Context, node, file string, params url.Values, header http.Header) (*http.Response, error) {
+	rc := c.clientset.CoreV1().RESTClient()
+	u := rc.Get().AbsPath(fmt.Sprintf("/api/v1/nodes/%s/proxy/logs/%s", node, file)).URL()
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	// the client of the RESTClient has the credentials to the API server
+	client := http.DefaultClient
+	if r, ok := rc.(*rest.RESTClient); ok && r.Client != nil {
+		client = r.Client
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get logs of %s", node)
+	}
+	return resp, nil
+}
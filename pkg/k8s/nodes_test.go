@@ -0,0 +1,236 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// kubeletServer is a stand-in API server serving logs of the node "node-1"
+// via the kubelet proxy.  The node "old-node" does not support the node log
+// query.
+type kubeletServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	files   map[string][]byte
+	journal []string
+	queries []string
+}
+
+func newKubeletServer() *kubeletServer {
+	s := &kubeletServer{files: make(map[string][]byte)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *kubeletServer) client(t *testing.T) *Client {
+	clientset, err := kubernetes.NewForConfig(&rest.Config{Host: s.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewClientForClientset(clientset)
+}
+
+func (s *kubeletServer) setFile(name, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = []byte(content)
+}
+
+func (s *kubeletServer) appendFile(name, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[name] = append(s.files[name], content...)
+}
+
+func (s *kubeletServer) appendJournal(lines ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journal = append(s.journal, lines...)
+}
+
+func (s *kubeletServer) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.SplitN(r.URL.Path, "/", 8)
+	if len(parts) != 8 || parts[5] != "proxy" || parts[6] != "logs" {
+		http.NotFound(w, r)
+		return
+	}
+	node, name := parts[4], parts[7]
+	if len(name) > 0 {
+		content, ok := s.files[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(content))
+		return
+	}
+
+	if query := r.URL.Query().Get("query"); len(query) > 0 && node != "old-node" {
+		s.queries = append(s.queries, r.URL.RawQuery)
+		since, _ := time.Parse(time.RFC3339, r.URL.Query().Get("sinceTime"))
+		w.Header().Set("Content-Type", "text/plain")
+		for _, line := range s.journal {
+			t := parseJournalLine(line, since.Year()).Time
+			if since.IsZero() || !t.Before(since) {
+				fmt.Fprintln(w, line)
+			}
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<pre>")
+	fmt.Fprintln(w, `<a href="containers/">containers/</a>`)
+	fmt.Fprintln(w, `<a href="kube-proxy.log">kube-proxy.log</a>`)
+	fmt.Fprintln(w, `<a href="syslog">syslog</a>`)
+	fmt.Fprintln(w, "</pre>")
+}
+
+func receiveLines(t *testing.T, ch <-chan LogLine, n int) []string {
+	t.Helper()
+	var lines []string
+	for len(lines) < n {
+		select {
+		case line := <-ch:
+			lines = append(lines, line.Text)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out: %q", lines)
+		}
+	}
+	return lines
+}
+
+func TestListNodeLogFiles(t *testing.T) {
+	s := newKubeletServer()
+	defer s.Close()
+
+	files, err := s.client(t).ListNodeLogFiles(context.Background(), "node-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"kube-proxy.log", "syslog"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("unexpected files: %q, want %q", files, expected)
+	}
+}
+
+func TestWatchNodeLogFile(t *testing.T) {
+	defer func(d time.Duration) { nodeLogPollInterval = d }(nodeLogPollInterval)
+	nodeLogPollInterval = 10 * time.Millisecond
+
+	s := newKubeletServer()
+	defer s.Close()
+	s.setFile("syslog", "line 1\nline 2\nline")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := s.client(t).WatchNodeLogFile(ctx, "node-1", "syslog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := receiveLines(t, ch, 2); !reflect.DeepEqual(lines, []string{"line 1", "line 2"}) {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+
+	// the line is sent after it is completed
+	s.appendFile("syslog", " 3\nline 4\n")
+	if lines := receiveLines(t, ch, 2); !reflect.DeepEqual(lines, []string{"line 3", "line 4"}) {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+
+	// the file is read from the beginning after truncated
+	s.setFile("syslog", "rotated\n")
+	if lines := receiveLines(t, ch, 1); !reflect.DeepEqual(lines, []string{"rotated"}) {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+
+	if _, err := s.client(t).WatchNodeLogFile(ctx, "node-1", "missing"); err == nil {
+		t.Error("expected error for the missing file")
+	}
+}
+
+func TestWatchNodeServiceLogs(t *testing.T) {
+	defer func(d time.Duration) { nodeLogPollInterval = d }(nodeLogPollInterval)
+	nodeLogPollInterval = 10 * time.Millisecond
+
+	s := newKubeletServer()
+	defer s.Close()
+	s.appendJournal(
+		"2019-07-01T21:00:00.100000+0900 node-1 kubelet[1]: started",
+		"2019-07-01T21:00:00.200000+0900 node-1 kubelet[1]: synced",
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := s.client(t).WatchNodeServiceLogs(ctx, "node-1", "kubelet", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := <-ch
+	if expected := time.Date(2019, 7, 1, 12, 0, 0, 100000000, time.UTC); !line.Time.Equal(expected) || line.Text != "node-1 kubelet[1]: started" {
+		t.Errorf("unexpected line: %v %q", line.Time, line.Text)
+	}
+	receiveLines(t, ch, 1)
+
+	// the lines in the same second of the last line are not sent again
+	s.appendJournal("2019-07-01T21:00:00.300000+0900 node-1 kubelet[1]: pod added")
+	if lines := receiveLines(t, ch, 1); !reflect.DeepEqual(lines, []string{"node-1 kubelet[1]: pod added"}) {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+
+	s.mu.Lock()
+	first, last := s.queries[0], s.queries[len(s.queries)-1]
+	s.mu.Unlock()
+	if first != "output=short-iso-precise&query=kubelet&tailLines=1000" || last != "output=short-iso-precise&query=kubelet&sinceTime=2019-07-01T12%3A00%3A00Z" {
+		t.Errorf("unexpected queries: %q, %q", first, last)
+	}
+
+	// the kubelet not supporting the query returns the list of the files
+	if _, err := s.client(t).WatchNodeServiceLogs(ctx, "old-node", "kubelet", time.Time{}); err == nil || !strings.Contains(err.Error(), "not supported") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestParseJournalLine(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected LogLine
+	}{
+		{
+			input:    "2019-07-01T21:00:00.123456+0900 node-1 kubelet[1]: hello",
+			expected: LogLine{Time: time.Date(2019, 7, 1, 12, 0, 0, 123456000, time.UTC), Text: "node-1 kubelet[1]: hello"},
+		},
+		{
+			input:    "2019-07-01T05:00:00.123456-07:00 node-1 kubelet[1]: hello",
+			expected: LogLine{Time: time.Date(2019, 7, 1, 12, 0, 0, 123456000, time.UTC), Text: "node-1 kubelet[1]: hello"},
+		},
+		{
+			input:    "Jul 01 12:00:00.123456 node-1 kubelet[1]: hello",
+			expected: LogLine{Time: time.Date(2019, 7, 1, 12, 0, 0, 123456000, time.UTC), Text: "node-1 kubelet[1]: hello"},
+		},
+		{
+			input:    "no timestamp in the line",
+			expected: LogLine{Text: "no timestamp in the line"},
+		},
+	}
+	for _, c := range cases {
+		l := parseJournalLine(c.input, 2019)
+		if !l.Time.Equal(c.expected.Time) || l.Text != c.expected.Text {
+			t.Errorf("parseJournalLine(%q) = %v, want %v", c.input, l, c.expected)
+		}
+	}
+}
@@ -24,10 +24,36 @@ type PodEvent struct {
 	Pod  *corev1.Pod
 }
 
+// PodOptions is options to list and watch pods
+type PodOptions struct {
+	// LabelSelector selects pods by their labels, such as "app=nginx"
+	LabelSelector string
+
+	// ResourceVersion is the version to start watching from.  Pass the
+	// version of the list returned by ListPods to watch changes after the
+	// list.
+	ResourceVersion string
+}
+
+// ListPods lists pods in namespace from Kubernetes API
+func (c *Client) ListPods(namespace string, opts PodOptions) (*corev1.PodList, error) {
+	return c.clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{
+		LabelSelector: opts.LabelSelector,
+	})
+}
+
+// GetPod gets the pod by the name in namespace from Kubernetes API
+func (c *Client) GetPod(namespace, name string) (*corev1.Pod, error) {
+	return c.clientset.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+}
+
 // WatchPods watches pods from Kubernetes API in namespace.  It returns a
 // channel to subscribe pods.
-func (c *Client) WatchPods(ctx context.Context, namespace string) (<-chan *PodEvent, error) {
-	r, err := c.clientset.CoreV1().Pods(namespace).Watch(metav1.ListOptions{})
+func (c *Client) WatchPods(ctx context.Context, namespace string, opts PodOptions) (<-chan *PodEvent, error) {
+	r, err := c.clientset.CoreV1().Pods(namespace).Watch(metav1.ListOptions{
+		LabelSelector:   opts.LabelSelector,
+		ResourceVersion: opts.ResourceVersion,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +63,8 @@ func (c *Client) WatchPods(ctx context.Context, namespace string) (<-chan *PodEv
 		r.Stop()
 	}()
 	go func() {
+		defer close(ch)
+
 		for ev := range r.ResultChan() {
 			pod, ok := ev.Object.(*corev1.Pod)
 			if !ok {
@@ -54,12 +82,12 @@ func (c *Client) WatchPods(ctx context.Context, namespace string) (<-chan *PodEv
 				continue
 			}
 
-			ch <- &PodEvent{
-				Type: t,
-				Pod:  pod,
+			select {
+			case ch <- &PodEvent{Type: t, Pod: pod}:
+			case <-ctx.Done():
+				return
 			}
 		}
-		close(ch)
 	}()
 	return ch, nil
 }
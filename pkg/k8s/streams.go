@@ -0,0 +1,178 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ContainerRef identifies a container of the pod
+type ContainerRef struct {
+	Namespace string
+	Pod       string
+	Container string
+}
+
+// LogHandler is a function to receive the log line of the container.  It is
+// invoked concurrently from the streams of the containers.
+type LogHandler func(ref ContainerRef, line LogLine)
+
+// LogStreams streams logs of containers in multiple pods concurrently.  The
+// stream of the restarted container is resumed from the last line.
+type LogStreams struct {
+	client  *Client
+	since   time.Time
+	handler LogHandler
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[ContainerRef]*logStream
+}
+
+type logStream struct {
+	running bool
+
+	// last is the timestamp of the last line received
+	last time.Time
+}
+
+// NewLogStreams returns a new LogStreams which sends lines to the handler.
+// The logs since the time are streamed if the since is not zero.
+func (c *Client) NewLogStreams(ctx context.Context, since time.Time, handler LogHandler) *LogStreams {
+	ctx, cancel := context.WithCancel(ctx)
+	return &LogStreams{
+		client:  c,
+		since:   since,
+		handler: handler,
+		ctx:     ctx,
+		cancel:  cancel,
+		streams: make(map[ContainerRef]*logStream),
+	}
+}
+
+// Watch starts streaming logs of the containers in the pod accepted by the
+// filter.  All containers are accepted if the filter is nil.  Containers being
+// streamed are skipped, so it can be called on each modification of the pod
+// to resume the restarted containers.  Containers not started yet are also
+// skipped.
+func (s *LogStreams) Watch(pod *corev1.Pod, filter func(container string) bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ctx.Err() != nil {
+		return
+	}
+	for _, name := range ContainerNames(pod) {
+		if filter != nil && !filter(name) {
+			continue
+		}
+		if !containerStarted(pod, name) {
+			continue
+		}
+		ref := ContainerRef{Namespace: pod.Namespace, Pod: pod.Name, Container: name}
+		st, ok := s.streams[ref]
+		if !ok {
+			st = &logStream{last: s.since}
+			s.streams[ref] = st
+		}
+		if st.running {
+			continue
+		}
+		st.running = true
+
+		s.wg.Add(1)
+		go func(ref ContainerRef, st *logStream, since time.Time) {
+			defer s.wg.Done()
+			last := s.stream(ref, since)
+
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			st.running = false
+			st.last = last
+		}(ref, st, st.last)
+	}
+}
+
+// stream sends logs of the container since the time to the handler.  It
+// returns the timestamp of the last line.
+func (s *LogStreams) stream(ref ContainerRef, since time.Time) time.Time {
+	opts := LogOptions{Timestamps: true, SinceTime: since}
+	logs, err := s.client.WatchLogs(s.ctx, ref.Namespace, ref.Pod, ref.Container, opts)
+	if err != nil {
+		// It is retried on the next modification of the pod
+		return since
+	}
+
+	last := since
+	for line := range logs {
+		// SinceTime is truncated to seconds; skip lines already received
+		if !since.IsZero() && !line.Time.After(since) {
+			continue
+		}
+		s.handler(ref, line)
+		if !line.Time.IsZero() {
+			last = line.Time
+		}
+	}
+	return last
+}
+
+// Running returns the count of the running streams
+func (s *LogStreams) Running() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int
+	for _, st := range s.streams {
+		if st.running {
+			n++
+		}
+	}
+	return n
+}
+
+// Wait waits for all running streams to finish
+func (s *LogStreams) Wait() {
+	s.wg.Wait()
+}
+
+// Close stops all streams and waits for them to finish
+func (s *LogStreams) Close() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// containerStarted returns true if the container in the pod has been started
+// and its logs are available
+func containerStarted(pod *corev1.Pod, name string) bool {
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, status := range statuses {
+			if status.Name == name {
+				return status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil
+			}
+		}
+	}
+	return false
+}
+
+// ContainerNames returns the names of the init containers and the containers
+// in the pod
+func ContainerNames(pod *corev1.Pod) []string {
+	return containerNames(&pod.Spec)
+}
+
+func containerNames(spec *corev1.PodSpec) []string {
+	names := make([]string, 0, len(spec.InitContainers)+len(spec.Containers))
+	for _, c := range spec.InitContainers {
+		names = append(names, c.Name)
+	}
+	for _, c := range spec.Containers {
+		names = append(names, c.Name)
+	}
+	return names
+}
@@ -0,0 +1,185 @@
+package k8s
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Workload is a controller which manages pods, such as a Deployment, a
+// StatefulSet, a DaemonSet, a Job or a CronJob
+type Workload struct {
+	Kind string
+	Name string
+}
+
+// String returns the workload as "Kind/Name"
+func (w Workload) String() string {
+	return w.Kind + "/" + w.Name
+}
+
+// WorkloadEventType represents an event type of the workload
+type WorkloadEventType int
+
+// The event type of the workloads
+const (
+	WorkloadAdded    WorkloadEventType = iota // The workload is added
+	WorkloadModified                          // The workload is updated
+	WorkloadDeleted                           // The workload is deleted
+)
+
+// WorkloadEvent represents an event of the workloads in Kubernetes API
+type WorkloadEvent struct {
+	Type     WorkloadEventType
+	Workload Workload
+
+	// Containers is the names of the init containers and the containers in
+	// the pod template of the workload
+	Containers []string
+
+	// Owner is the workload controlling the workload, such as the CronJob
+	// of the Job.  It is nil if the workload has no controller.
+	Owner *Workload
+}
+
+// GetWorkload returns the workload controlling the pod.  The ReplicaSet is
+// resolved to the Deployment owning it, and the Job is resolved to the
+// CronJob owning it.  It returns nil if the pod has no controller.
+func (c *Client) GetWorkload(pod *corev1.Pod) (*Workload, error) {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return nil, nil
+	}
+	w := &Workload{Kind: owner.Kind, Name: owner.Name}
+
+	var obj metav1.Object
+	var err error
+	switch owner.Kind {
+	case "ReplicaSet":
+		obj, err = c.clientset.AppsV1().ReplicaSets(pod.Namespace).Get(owner.Name, metav1.GetOptions{})
+	case "Job":
+		obj, err = c.clientset.BatchV1().Jobs(pod.Namespace).Get(owner.Name, metav1.GetOptions{})
+	default:
+		return w, nil
+	}
+	if err != nil {
+		return w, errors.Wrapf(err, "failed to get %s %s", owner.Kind, owner.Name)
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		w = &Workload{Kind: owner.Kind, Name: owner.Name}
+	}
+	return w, nil
+}
+
+// WatchWorkloads watches Deployments, StatefulSets, DaemonSets, Jobs and
+// CronJobs from Kubernetes API in namespace.  It returns a channel to
+// subscribe the workloads.  The channel is closed when ctx is done.
+func (c *Client) WatchWorkloads(ctx context.Context, namespace string) (<-chan *WorkloadEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	opts := metav1.ListOptions{}
+	watchers := []func() (watch.Interface, error){
+		func() (watch.Interface, error) { return c.clientset.AppsV1().Deployments(namespace).Watch(opts) },
+		func() (watch.Interface, error) { return c.clientset.AppsV1().StatefulSets(namespace).Watch(opts) },
+		func() (watch.Interface, error) { return c.clientset.AppsV1().DaemonSets(namespace).Watch(opts) },
+		func() (watch.Interface, error) { return c.clientset.BatchV1().Jobs(namespace).Watch(opts) },
+		func() (watch.Interface, error) { return c.clientset.BatchV1beta1().CronJobs(namespace).Watch(opts) },
+	}
+	var rs []watch.Interface
+	for _, f := range watchers {
+		r, err := f()
+		if err != nil {
+			cancel()
+			for _, r := range rs {
+				r.Stop()
+			}
+			return nil, errors.Wrap(err, "failed to watch workloads")
+		}
+		rs = append(rs, r)
+	}
+
+	ch := make(chan *WorkloadEvent)
+	var wg sync.WaitGroup
+	for _, r := range rs {
+		wg.Add(1)
+		go func(r watch.Interface) {
+			defer wg.Done()
+			defer r.Stop()
+			for {
+				select {
+				case ev, ok := <-r.ResultChan():
+					if !ok {
+						return
+					}
+					we := workloadEvent(ev)
+					if we == nil {
+						continue
+					}
+					select {
+					case ch <- we:
+					case <-ctx.Done():
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(r)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// workloadEvent converts the event of the watch to WorkloadEvent.  It returns
+// nil if the event is not of workloads.
+func workloadEvent(ev watch.Event) *WorkloadEvent {
+	var t WorkloadEventType
+	switch ev.Type {
+	case watch.Added:
+		t = WorkloadAdded
+	case watch.Modified:
+		t = WorkloadModified
+	case watch.Deleted:
+		t = WorkloadDeleted
+	default:
+		return nil
+	}
+
+	var kind string
+	var spec corev1.PodSpec
+	switch o := ev.Object.(type) {
+	case *appsv1.Deployment:
+		kind, spec = "Deployment", o.Spec.Template.Spec
+	case *appsv1.StatefulSet:
+		kind, spec = "StatefulSet", o.Spec.Template.Spec
+	case *appsv1.DaemonSet:
+		kind, spec = "DaemonSet", o.Spec.Template.Spec
+	case *batchv1.Job:
+		kind, spec = "Job", o.Spec.Template.Spec
+	case *batchv1beta1.CronJob:
+		kind, spec = "CronJob", o.Spec.JobTemplate.Spec.Template.Spec
+	default:
+		return nil
+	}
+	obj := ev.Object.(metav1.Object)
+
+	we := &WorkloadEvent{
+		Type:       t,
+		Workload:   Workload{Kind: kind, Name: obj.GetName()},
+		Containers: containerNames(&spec),
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		we.Owner = &Workload{Kind: owner.Kind, Name: owner.Name}
+	}
+	return we
+}
@@ -0,0 +1,140 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	controller := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+}
+
+func TestGetWorkload(t *testing.T) {
+	client := NewClientForClientset(fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "web-5d4f", Namespace: "default", OwnerReferences: controllerRef("Deployment", "web"),
+		}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: "bare-8a7b", Namespace: "default",
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "migrate", Namespace: "default",
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: "backup-1562025600", Namespace: "default", OwnerReferences: controllerRef("CronJob", "backup"),
+		}},
+	))
+
+	cases := []struct {
+		owners   []metav1.OwnerReference
+		expected string
+	}{
+		{owners: controllerRef("ReplicaSet", "web-5d4f"), expected: "Deployment/web"},
+		{owners: controllerRef("ReplicaSet", "bare-8a7b"), expected: "ReplicaSet/bare-8a7b"},
+		{owners: controllerRef("StatefulSet", "db"), expected: "StatefulSet/db"},
+		{owners: controllerRef("Job", "migrate"), expected: "Job/migrate"},
+		{owners: controllerRef("Job", "backup-1562025600"), expected: "CronJob/backup"},
+		{owners: controllerRef("DaemonSet", "fluentd"), expected: "DaemonSet/fluentd"},
+		{owners: nil, expected: ""},
+	}
+	for _, c := range cases {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name: "pod", Namespace: "default", OwnerReferences: c.owners,
+		}}
+		w, err := client.GetWorkload(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual string
+		if w != nil {
+			actual = w.String()
+		}
+		if actual != c.expected {
+			t.Errorf("unexpected workload: %q, want %q", actual, c.expected)
+		}
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name: "pod", Namespace: "default", OwnerReferences: controllerRef("ReplicaSet", "missing"),
+	}}
+	w, err := client.GetWorkload(pod)
+	if err == nil || w == nil || w.String() != "ReplicaSet/missing" {
+		t.Errorf("unexpected result: %v, %v", w, err)
+	}
+}
+
+func TestWatchWorkloads(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := NewClientForClientset(clientset)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := client.WatchWorkloads(ctx, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init"}},
+		Containers:     []corev1.Container{{Name: "app"}},
+	}}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Template: template},
+	}
+	if _, err := clientset.AppsV1().Deployments("default").Create(deploy); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	expected := &WorkloadEvent{
+		Type:       WorkloadAdded,
+		Workload:   Workload{Kind: "Deployment", Name: "web"},
+		Containers: []string{"init", "app"},
+	}
+	if !reflect.DeepEqual(ev, expected) {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	cronJob := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"},
+		Spec: batchv1beta1.CronJobSpec{JobTemplate: batchv1beta1.JobTemplateSpec{
+			Spec: batchv1.JobSpec{Template: template},
+		}},
+	}
+	if _, err := clientset.BatchV1beta1().CronJobs("default").Create(cronJob); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Workload.String() != "CronJob/backup" || len(ev.Containers) != 2 {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name: "backup-1562025600", Namespace: "default", OwnerReferences: controllerRef("CronJob", "backup"),
+	}}
+	if _, err := clientset.BatchV1().Jobs("default").Create(job); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Workload.String() != "Job/backup-1562025600" || ev.Owner == nil || ev.Owner.String() != "CronJob/backup" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	if err := clientset.AppsV1().Deployments("default").Delete("web", &metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-events; ev.Type != WorkloadDeleted || ev.Workload.String() != "Deployment/web" {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	cancel()
+	for range events {
+	}
+}
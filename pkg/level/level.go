@@ -0,0 +1,104 @@
+package level
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ueokande/logbook/pkg/ansi"
+)
+
+// Level represents a severity of the log line
+type Level int
+
+// The levels of the logs in ascending order of the severity
+const (
+	Unknown Level = iota // The level is not detected
+	Trace                // Trace level
+	Debug                // Debug level
+	Info                 // Info level
+	Warn                 // Warning level
+	Error                // Error level, including fatal and critical
+)
+
+// detectLength is the length of the head of the line in which the bare
+// level names are looked up
+const detectLength = 128
+
+var (
+	klogPattern     = regexp.MustCompile(`^([IWEF])\d{4} `)
+	keyValuePattern = regexp.MustCompile(`(?i)(?:^|[\s,{])"?(?:level|lvl|severity)"?\s*[=:]\s*"?([A-Za-z]+)`)
+	bracketPattern  = regexp.MustCompile(`\[([A-Za-z]+)\s*\]`)
+	wordPattern     = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|FATAL|CRITICAL|SEVERE|PANIC)\b`)
+)
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case Trace:
+		return "TRACE"
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	}
+	return ""
+}
+
+// Parse returns the level by the name such as "info" or "WARNING".  It
+// returns Unknown if the name is not a level.
+func Parse(name string) Level {
+	switch strings.ToLower(name) {
+	case "trace", "finest", "finer":
+		return Trace
+	case "debug", "fine", "config":
+		return Debug
+	case "info", "information", "notice":
+		return Info
+	case "warn", "warning":
+		return Warn
+	case "error", "err", "fatal", "critical", "crit", "panic", "alert", "emerg", "emergency", "severe":
+		return Error
+	}
+	return Unknown
+}
+
+// Detect detects the level of the log line.  It recognizes the logfmt
+// (level=info), JSON ("level":"info"), klog (E0601), bracketed ([WARN]) and
+// the bare level name in the head of the line (Python and Java style).
+func Detect(line string) Level {
+	line = ansi.Strip(line)
+
+	if m := klogPattern.FindStringSubmatch(line); m != nil {
+		switch m[1] {
+		case "I":
+			return Info
+		case "W":
+			return Warn
+		case "E", "F":
+			return Error
+		}
+	}
+	if m := keyValuePattern.FindStringSubmatch(line); m != nil {
+		if l := Parse(m[1]); l != Unknown {
+			return l
+		}
+	}
+
+	head := line
+	if len(head) > detectLength {
+		head = head[:detectLength]
+	}
+	for _, m := range bracketPattern.FindAllStringSubmatch(head, -1) {
+		if l := Parse(m[1]); l != Unknown {
+			return l
+		}
+	}
+	if m := wordPattern.FindStringSubmatch(head); m != nil {
+		return Parse(m[1])
+	}
+	return Unknown
+}
@@ -0,0 +1,48 @@
+package level
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		line     string
+		expected Level
+	}{
+		{`time="2019-07-01T00:00:00Z" level=warning msg="disk is full"`, Warn},
+		{`ts=2019-07-01T00:00:00Z lvl=debug caller=main.go:10`, Debug},
+		{`{"level":"error","msg":"failed"}`, Error},
+		{`{"severity": "INFO", "message": "ok"}`, Info},
+		{`E0601 12:00:00.000000       1 controller.go:100] sync failed`, Error},
+		{`W0601 12:00:00.000000       1 controller.go:100] retrying`, Warn},
+		{`I0601 12:00:00.000000       1 controller.go:100] synced`, Info},
+		{`2019-07-01 12:00:00 [main] [WARN ] low memory`, Warn},
+		{`[debug] connecting`, Debug},
+		{`ERROR:root:division by zero`, Error},
+		{`2019-07-01 12:00:00,000 - app - CRITICAL - out of memory`, Error},
+		{`2019-07-01 12:00:00.000 INFO  [main] o.s.b.Application : Started`, Info},
+		{"\x1b[31mERROR\x1b[0m something", Error},
+		{`	at com.example.Main.main(Main.java:10)`, Unknown},
+		{`the information is missing`, Unknown},
+	}
+
+	for _, c := range cases {
+		if l := Detect(c.line); l != c.expected {
+			t.Errorf("Detect(%q) = %v, want %v", c.line, l, c.expected)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]Level{
+		"trace":   Trace,
+		"DEBUG":   Debug,
+		"Info":    Info,
+		"warning": Warn,
+		"fatal":   Error,
+		"verbose": Unknown,
+	}
+	for name, expected := range cases {
+		if l := Parse(name); l != expected {
+			t.Errorf("Parse(%q) = %v, want %v", name, l, expected)
+		}
+	}
+}
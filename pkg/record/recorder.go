@@ -0,0 +1,115 @@
+package record
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const flushInterval = time.Second
+
+// Recorder records logs of all containers in pods to files in the directory.
+// The logs of the container are written to "<dir>/<namespace>/<pod>/<container>.log".
+type Recorder struct {
+	dir        string
+	maxSize    int64
+	maxBackups int
+	streams    *k8s.LogStreams
+	done       chan struct{}
+	flushed    chan struct{}
+
+	mu      sync.Mutex
+	writers map[k8s.ContainerRef]*RotateWriter
+	err     error
+}
+
+// NewRecorder returns a new Recorder to record logs into dir.  Files are
+// rotated when its size exceeds maxSize, and maxBackups rotated files are
+// kept.
+func NewRecorder(client *k8s.Client, dir string, maxSize int64, maxBackups int) *Recorder {
+	r := &Recorder{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		done:       make(chan struct{}),
+		flushed:    make(chan struct{}),
+		writers:    make(map[k8s.ContainerRef]*RotateWriter),
+	}
+	r.streams = client.NewLogStreams(context.Background(), time.Time{}, r.write)
+	go r.flushLoop()
+	return r
+}
+
+// RecordPod starts recording logs of containers in the pod.  Containers
+// already recorded are skipped, so it can be called on each modification of
+// the pod to record restarted containers.
+func (r *Recorder) RecordPod(pod *corev1.Pod) {
+	r.streams.Watch(pod, nil)
+}
+
+func (r *Recorder) write(ref k8s.ContainerRef, line k8s.LogLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w, ok := r.writers[ref]
+	if !ok {
+		var err error
+		path := filepath.Join(r.dir, ref.Namespace, ref.Pod, ref.Container+".log")
+		w, err = NewRotateWriter(path, r.maxSize, r.maxBackups)
+		if err != nil {
+			r.err = err
+			return
+		}
+		r.writers[ref] = w
+	}
+	s := line.Text + "\n"
+	if !line.Time.IsZero() {
+		s = line.Time.Format(time.RFC3339Nano) + " " + s
+	}
+	if _, err := w.Write([]byte(s)); err != nil {
+		r.err = err
+	}
+}
+
+// flushLoop flushes files periodically not to lose logs of quiet containers
+// on a crash
+func (r *Recorder) flushLoop() {
+	defer close(r.flushed)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.mu.Lock()
+			for _, w := range r.writers {
+				if err := w.Flush(); err != nil {
+					r.err = err
+				}
+			}
+			r.mu.Unlock()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+// Close stops recording and flushes all files.  It returns the last error
+// occurred on recording.
+func (r *Recorder) Close() error {
+	r.streams.Close()
+	close(r.done)
+	<-r.flushed
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, w := range r.writers {
+		if err := w.Close(); err != nil {
+			r.err = err
+		}
+	}
+	return r.err
+}
@@ -0,0 +1,38 @@
+package record
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewRecorder(k8s.NewClientForClientset(fake.NewSimpleClientset()), dir, 1024, 1)
+	ref := k8s.ContainerRef{Namespace: "ns", Pod: "pod", Container: "app"}
+	t0 := time.Date(2019, 6, 1, 12, 0, 0, 500, time.UTC)
+	r.write(ref, k8s.LogLine{Time: t0, Text: "timed"})
+	r.write(ref, k8s.LogLine{Text: "untimed"})
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "ns", "pod", "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "2019-06-01T12:00:00.0000005Z timed\nuntimed\n"
+	if string(b) != expected {
+		t.Errorf("unexpected content: %q, want %q", string(b), expected)
+	}
+}
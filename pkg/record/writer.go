@@ -0,0 +1,108 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// RotateWriter is a writer to the file which is rotated by its size.  The
+// rotated files are renamed with suffixes ".1", ".2", and so on, and files
+// older than maxBackups are removed.
+type RotateWriter struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	file *os.File
+	buf  *bufio.Writer
+	size int64
+}
+
+// NewRotateWriter opens the file at path to append, and returns a new
+// RotateWriter.  Parent directories are created if they do not exist.  The
+// file is never rotated if the maxSize is zero.
+func NewRotateWriter(path string, maxSize int64, maxBackups int) (*RotateWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, errors.Wrap(err, "failed to create directory")
+	}
+	w := &RotateWriter{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *RotateWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "failed to stat file")
+	}
+	w.file = f
+	w.buf = bufio.NewWriter(f)
+	w.size = info.Size()
+	return nil
+}
+
+// Write writes p to the file.  The file is rotated before writing if the
+// size exceeds the max size.
+func (w *RotateWriter) Write(p []byte) (int, error) {
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.buf.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotateWriter) rotate() error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	err := os.Remove(w.backupPath(w.maxBackups))
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove file")
+	}
+	for i := w.maxBackups; i > 0; i-- {
+		err := os.Rename(w.backupPath(i-1), w.backupPath(i))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "failed to rotate file")
+		}
+	}
+	return w.open()
+}
+
+func (w *RotateWriter) backupPath(i int) string {
+	if i == 0 {
+		return w.path
+	}
+	return fmt.Sprintf("%s.%d", w.path, i)
+}
+
+// Flush writes buffered data to the file
+func (w *RotateWriter) Flush() error {
+	return w.buf.Flush()
+}
+
+// Close flushes buffered data and closes the file
+func (w *RotateWriter) Close() error {
+	err := w.buf.Flush()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
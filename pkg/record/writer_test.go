@@ -0,0 +1,59 @@
+package record
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotateWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "ns", "pod", "app.log")
+	w, err := NewRotateWriter(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"line1\n", "line2\n", "line3\n", "line4\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		path:        "line4\n",
+		path + ".1": "line3\n",
+		path + ".2": "line2\n",
+	}
+	for p, content := range expected {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Errorf("%s: %q != %q", p, string(b), content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 should not exist", path)
+	}
+
+	w, err = NewRotateWriter(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("more\n"))
+	w.Close()
+	b, _ := ioutil.ReadFile(path + ".1")
+	if string(b) != "line4\n" {
+		t.Errorf("existing file should be rotated: %q", string(b))
+	}
+}
@@ -0,0 +1,201 @@
+package source
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/docker"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+// dockerPollInterval is the interval to list containers in Docker Engine
+var dockerPollInterval = 2 * time.Second
+
+var exitCodePattern = regexp.MustCompile(`^Exited \((\d+)\)`)
+
+// Docker is a Source of local containers in Docker Engine.  Containers
+// created by docker compose are grouped by the compose project, and their
+// services are shown as the containers of the target.  Other containers are
+// shown as a target for each container.
+type Docker struct {
+	client *docker.Client
+
+	mu  sync.Mutex
+	ids map[containerKey]string
+}
+
+// NewDocker returns a new Docker source with the client
+func NewDocker(client *docker.Client) *Docker {
+	return &Docker{
+		client: client,
+		ids:    make(map[containerKey]string),
+	}
+}
+
+// WatchTargets lists containers periodically, and returns a channel to
+// subscribe changes of the targets
+func (s *Docker) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	containers, err := s.client.ListContainers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+		current := make(map[string]*Target)
+		for {
+			targets := s.update(containers)
+			for _, ev := range diffTargets(current, targets) {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+			current = targets
+
+			select {
+			case <-time.After(dockerPollInterval):
+			case <-ctx.Done():
+				return
+			}
+			// keep the last targets on errors, and retry on the next time
+			if c, err := s.client.ListContainers(ctx); err == nil {
+				containers = c
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StreamLogs follows logs of the container in the target
+func (s *Docker) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	s.mu.Lock()
+	id, ok := s.ids[containerKey{target: target, container: container}]
+	s.mu.Unlock()
+	if !ok {
+		return nil, errors.Errorf("container %s not found in %s", container, target)
+	}
+
+	logs, err := s.client.Logs(ctx, id, docker.LogOptions{Follow: true, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for line := range logs {
+			select {
+			case ch <- LogLine{Time: line.Time, Text: line.Text}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// update updates IDs of the containers, and returns the targets of the
+// containers by the names
+func (s *Docker) update(containers []docker.Container) map[string]*Target {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// count containers of the services to name replicas by the container
+	replicas := make(map[containerKey]int)
+	for _, c := range containers {
+		if project, ok := c.Labels[docker.LabelComposeProject]; ok {
+			replicas[containerKey{target: project, container: c.Labels[docker.LabelComposeService]}]++
+		}
+	}
+
+	targets := make(map[string]*Target)
+	states := make(map[string][]docker.Container)
+	s.ids = make(map[containerKey]string)
+	for _, c := range containers {
+		target, name := c.Name(), c.Name()
+		if project, ok := c.Labels[docker.LabelComposeProject]; ok {
+			target = project
+			service := c.Labels[docker.LabelComposeService]
+			if replicas[containerKey{target: project, container: service}] == 1 {
+				name = service
+			}
+		}
+
+		t, ok := targets[target]
+		if !ok {
+			t = &Target{Name: target}
+			targets[target] = t
+		}
+		t.Containers = append(t.Containers, name)
+		states[target] = append(states[target], c)
+		s.ids[containerKey{target: target, container: name}] = c.ID
+	}
+	for name, t := range targets {
+		sort.Strings(t.Containers)
+		t.Status = dockerStatus(states[name])
+	}
+	return targets
+}
+
+// dockerStatus returns the status of the target from its containers.  It is
+// failed if any container is restarting or exited with non-zero code, and
+// succeeded if all containers exited with code 0.
+func dockerStatus(containers []docker.Container) types.PodStatus {
+	var running, pending bool
+	for _, c := range containers {
+		switch c.State {
+		case "running", "paused":
+			running = true
+		case "created":
+			pending = true
+		case "restarting", "dead":
+			return types.PodFailed
+		case "exited":
+			if m := exitCodePattern.FindStringSubmatch(c.Status); m != nil && m[1] != "0" {
+				return types.PodFailed
+			}
+		case "removing":
+			return types.PodTerminating
+		default:
+			return types.PodUnknown
+		}
+	}
+	switch {
+	case running:
+		return types.PodRunning
+	case pending:
+		return types.PodPending
+	}
+	return types.PodSucceeded
+}
+
+// diffTargets returns events to update the targets from old to new.  The
+// events are sorted by the name of the targets.
+func diffTargets(old, new map[string]*Target) []*Event {
+	var events []*Event
+	for name, t := range new {
+		o, ok := old[name]
+		switch {
+		case !ok:
+			events = append(events, &Event{Type: TargetAdded, Target: t})
+		case !reflect.DeepEqual(o, t):
+			events = append(events, &Event{Type: TargetModified, Target: t})
+		}
+	}
+	for name, t := range old {
+		if _, ok := new[name]; !ok {
+			events = append(events, &Event{Type: TargetDeleted, Target: t})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Target.Name < events[j].Target.Name
+	})
+	return events
+}
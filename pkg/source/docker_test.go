@@ -0,0 +1,61 @@
+package source
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ueokande/logbook/pkg/docker"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func composeContainer(id, name, project, service, state, status string) docker.Container {
+	return docker.Container{
+		ID:     id,
+		Names:  []string{"/" + name},
+		State:  state,
+		Status: status,
+		Labels: map[string]string{
+			docker.LabelComposeProject: project,
+			docker.LabelComposeService: service,
+		},
+	}
+}
+
+func TestDockerTargets(t *testing.T) {
+	s := NewDocker(nil)
+	targets := s.update([]docker.Container{
+		composeContainer("1", "shop-web-1", "shop", "web", "running", "Up 5 minutes"),
+		composeContainer("2", "shop-worker-1", "shop", "worker", "running", "Up 5 minutes"),
+		composeContainer("3", "shop-worker-2", "shop", "worker", "exited", "Exited (0) 1 minute ago"),
+		composeContainer("4", "batch-job-1", "batch", "job", "exited", "Exited (1) 1 minute ago"),
+		{ID: "5", Names: []string{"/redis"}, State: "exited", Status: "Exited (0) 1 hour ago"},
+	})
+
+	expected := map[string]*Target{
+		"shop":  {Name: "shop", Status: types.PodRunning, Containers: []string{"shop-worker-1", "shop-worker-2", "web"}},
+		"batch": {Name: "batch", Status: types.PodFailed, Containers: []string{"job"}},
+		"redis": {Name: "redis", Status: types.PodSucceeded, Containers: []string{"redis"}},
+	}
+	if !reflect.DeepEqual(targets, expected) {
+		for name, t2 := range targets {
+			t.Logf("%s: %+v", name, t2)
+		}
+		t.Error("unexpected targets")
+	}
+	if id := s.ids[containerKey{target: "shop", container: "shop-worker-2"}]; id != "3" {
+		t.Errorf("unexpected id: %s", id)
+	}
+
+	updated := map[string]*Target{
+		"shop":  {Name: "shop", Status: types.PodFailed, Containers: []string{"web"}},
+		"redis": expected["redis"],
+		"db":    {Name: "db", Status: types.PodPending, Containers: []string{"db"}},
+	}
+	var events []string
+	for _, ev := range diffTargets(expected, updated) {
+		events = append(events, []string{"added", "modified", "deleted"}[ev.Type]+" "+ev.Target.Name)
+	}
+	if !reflect.DeepEqual(events, []string{"deleted batch", "added db", "modified shop"}) {
+		t.Errorf("unexpected events: %q", events)
+	}
+}
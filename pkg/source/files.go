@@ -0,0 +1,298 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+// StdinPath is the path to read logs from stdin
+const StdinPath = "-"
+
+// stdinName is the name of the target of stdin
+const stdinName = "<stdin>"
+
+// pollInterval is the interval to check the growth and the rotation of files
+var pollInterval = 250 * time.Millisecond
+
+// Files is a Source of local log files.  Each file is a target which has a
+// single container.  The files are followed like "tail -F"; the file is
+// reopened when it is rotated or truncated.
+type Files struct {
+	paths []string
+	stdin io.Reader
+
+	stdinOnce sync.Once
+	stdinBuf  *lineBuffer
+}
+
+// NewFiles returns a new Files source of the paths.  The path "-" reads logs
+// from stdin.
+func NewFiles(paths []string, stdin io.Reader) *Files {
+	return &Files{
+		paths:    paths,
+		stdin:    stdin,
+		stdinBuf: newLineBuffer(),
+	}
+}
+
+// WatchTargets returns a channel to subscribe the files.  The status of the
+// file is Pending while the file does not exist, and stdin is Succeeded
+// after it is closed.
+func (s *Files) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	for _, path := range s.paths {
+		if path == StdinPath {
+			s.stdinOnce.Do(func() {
+				go s.stdinBuf.readFrom(s.stdin)
+			})
+		}
+	}
+
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+		statuses := make([]types.PodStatus, len(s.paths))
+		for {
+			for i, path := range s.paths {
+				status := s.status(path)
+				if status == statuses[i] {
+					continue
+				}
+				t := TargetModified
+				if len(statuses[i]) == 0 {
+					t = TargetAdded
+				}
+				statuses[i] = status
+
+				select {
+				case ch <- &Event{Type: t, Target: s.target(path, status)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case <-time.After(pollInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StreamLogs follows lines of the file named target.  The since is ignored
+// because lines in the files have no timestamps.
+func (s *Files) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	path, ok := s.path(target)
+	if !ok {
+		return nil, errors.Errorf("no such file: %s", target)
+	}
+
+	ch := make(chan LogLine)
+	send := func(text string) bool {
+		select {
+		case ch <- LogLine{Text: text}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	go func() {
+		defer close(ch)
+		if path == StdinPath {
+			s.stdinBuf.follow(ctx, send)
+			return
+		}
+		followFile(ctx, path, send)
+	}()
+	return ch, nil
+}
+
+func (s *Files) status(path string) types.PodStatus {
+	if path == StdinPath {
+		if s.stdinBuf.closed() {
+			return types.PodSucceeded
+		}
+		return types.PodRunning
+	}
+	if _, err := os.Stat(path); err != nil {
+		return types.PodPending
+	}
+	return types.PodRunning
+}
+
+func (s *Files) target(path string, status types.PodStatus) *Target {
+	if path == StdinPath {
+		return &Target{Name: stdinName, Status: status, Containers: []string{"stdin"}}
+	}
+	return &Target{Name: path, Status: status, Containers: []string{filepath.Base(path)}}
+}
+
+func (s *Files) path(target string) (string, bool) {
+	for _, path := range s.paths {
+		if path == target || (path == StdinPath && target == stdinName) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// followFile sends lines of the file at path to send until ctx is done or
+// send returns false.  It waits for the file to be created if it does not
+// exist, and reopens the file if it is rotated or truncated.
+func followFile(ctx context.Context, path string, send func(string) bool) {
+	var f *os.File
+	var r *bufio.Reader
+	var offset int64
+	var partial string
+	defer func() {
+		if f != nil {
+			f.Close()
+		}
+	}()
+
+	// readLines reads lines until EOF.  It returns false if send returns
+	// false.
+	readLines := func() bool {
+		for {
+			line, err := r.ReadString('\n')
+			offset += int64(len(line))
+			if err != nil {
+				// keep the incomplete line until the rest is written
+				partial += line
+				return true
+			}
+			if !send(strings.TrimRight(partial+line, "\r\n")) {
+				return false
+			}
+			partial = ""
+		}
+	}
+
+	for {
+		if f == nil {
+			var err error
+			if f, err = os.Open(path); err == nil {
+				r = bufio.NewReader(f)
+				offset = 0
+			} else {
+				f = nil
+			}
+		}
+
+		if f != nil {
+			if !readLines() {
+				return
+			}
+
+			fi, ferr := f.Stat()
+			st, serr := os.Stat(path)
+			switch {
+			case serr != nil || ferr != nil || !os.SameFile(fi, st):
+				// the file is rotated; the lines can be written to the old
+				// file after reading it and before rotating it
+				if !readLines() {
+					return
+				}
+				if len(partial) > 0 && !send(partial) {
+					return
+				}
+				partial = ""
+				f.Close()
+				f = nil
+				if serr == nil {
+					continue
+				}
+			case st.Size() < offset:
+				// the file is truncated
+				partial = ""
+				offset = 0
+				if _, err := f.Seek(0, io.SeekStart); err == nil {
+					r.Reset(f)
+					continue
+				}
+				f.Close()
+				f = nil
+			}
+		}
+
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// lineBuffer keeps lines read from the reader to stream them multiple times
+type lineBuffer struct {
+	mu     sync.Mutex
+	lines  []string
+	done   bool
+	notify chan struct{}
+}
+
+func newLineBuffer() *lineBuffer {
+	return &lineBuffer{notify: make(chan struct{})}
+}
+
+func (b *lineBuffer) readFrom(r io.Reader) {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		b.mu.Lock()
+		b.lines = append(b.lines, s.Text())
+		close(b.notify)
+		b.notify = make(chan struct{})
+		b.mu.Unlock()
+	}
+
+	b.mu.Lock()
+	b.done = true
+	close(b.notify)
+	b.mu.Unlock()
+}
+
+func (b *lineBuffer) closed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.done
+}
+
+// follow sends all lines and lines appended later to send until the reader
+// is closed, ctx is done or send returns false
+func (b *lineBuffer) follow(ctx context.Context, send func(string) bool) {
+	var i int
+	for {
+		b.mu.Lock()
+		lines := b.lines[i:]
+		done := b.done
+		notify := b.notify
+		b.mu.Unlock()
+
+		for _, line := range lines {
+			if !send(line) {
+				return
+			}
+		}
+		i += len(lines)
+		if done {
+			return
+		}
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package source
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func init() {
+	pollInterval = 10 * time.Millisecond
+}
+
+func receiveLines(t *testing.T, ch <-chan LogLine, n int) []string {
+	var lines []string
+	for len(lines) < n {
+		select {
+		case line := <-ch:
+			lines = append(lines, line.Text)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out: received %q", lines)
+		}
+	}
+	return lines
+}
+
+func TestFilesStreamLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook-source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.log")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := NewFiles([]string{path}, nil)
+	ch, err := src.StreamLogs(ctx, path, "app.log", time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the file is created after streaming started
+	ioutil.WriteFile(path, []byte("line1\nline2\npart"), 0644)
+	lines := receiveLines(t, ch, 2)
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString("ial\n")
+	f.Close()
+	lines = append(lines, receiveLines(t, ch, 1)...)
+
+	// rotation
+	os.Rename(path, path+".1")
+	ioutil.WriteFile(path, []byte("rotated\n"), 0644)
+	lines = append(lines, receiveLines(t, ch, 1)...)
+
+	// truncation
+	ioutil.WriteFile(path, []byte("x\n"), 0644)
+	lines = append(lines, receiveLines(t, ch, 1)...)
+
+	expected := "line1,line2,partial,rotated,x"
+	if strings.Join(lines, ",") != expected {
+		t.Errorf("expected %q, actual %q", expected, lines)
+	}
+}
+
+func TestFilesStdin(t *testing.T) {
+	src := NewFiles([]string{StdinPath}, strings.NewReader("hello\nworld\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := src.WatchTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := <-events
+	if ev.Type != TargetAdded || ev.Target.Name != stdinName {
+		t.Errorf("unexpected event: %+v", ev.Target)
+	}
+
+	// stdin can be streamed multiple times
+	for i := 0; i < 2; i++ {
+		ch, err := src.StreamLogs(ctx, stdinName, "stdin", time.Time{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var lines []string
+		for line := range ch {
+			lines = append(lines, line.Text)
+		}
+		if strings.Join(lines, ",") != "hello,world" {
+			t.Errorf("unexpected lines: %q", lines)
+		}
+	}
+	if src.status(StdinPath) != types.PodSucceeded {
+		t.Errorf("stdin is not succeeded")
+	}
+
+	_, err = src.StreamLogs(ctx, "missing", "missing", time.Time{})
+	if err == nil {
+		t.Error("expected error for unknown target")
+	}
+}
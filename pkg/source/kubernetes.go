@@ -0,0 +1,192 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Events is the name of the virtual target streaming events in the namespace,
+// and the virtual container streaming events of the pod.  It never conflicts
+// with names of pods and containers which are lower case.
+const Events = "Events"
+
+// Kubernetes is a Source of pods and their containers in the namespace
+type Kubernetes struct {
+	client    *k8s.Client
+	namespace string
+	selector  string
+	podFunc   func(pod *corev1.Pod)
+}
+
+// NewKubernetes returns a new Kubernetes source of the pods in namespace
+// selected by the label selector
+func NewKubernetes(client *k8s.Client, namespace, selector string) *Kubernetes {
+	return &Kubernetes{
+		client:    client,
+		namespace: namespace,
+		selector:  selector,
+	}
+}
+
+// OnPodChanged sets the function invoked with the pod on added or modified,
+// such as to record logs of the pod.  It is invoked on the goroutine
+// watching pods.
+func (s *Kubernetes) OnPodChanged(f func(pod *corev1.Pod)) {
+	s.podFunc = f
+}
+
+// WatchTargets watches pods and returns a channel to subscribe them as the
+// targets.  The virtual target of events in the namespace is sent first.
+func (s *Kubernetes) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	events, err := s.client.WatchPods(ctx, s.namespace, k8s.PodOptions{LabelSelector: s.selector})
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+
+		select {
+		case ch <- &Event{Type: TargetAdded, Target: eventsTarget()}:
+		case <-ctx.Done():
+			return
+		}
+
+		// workloads caches the workloads resolved by the controllers of pods
+		workloads := make(map[string]string)
+		for ev := range events {
+			var t EventType
+			switch ev.Type {
+			case k8s.PodAdded:
+				t = TargetAdded
+			case k8s.PodModified:
+				t = TargetModified
+			case k8s.PodDeleted:
+				t = TargetDeleted
+			}
+			if t != TargetDeleted && s.podFunc != nil {
+				s.podFunc(ev.Pod)
+			}
+
+			target := podTarget(ev.Pod)
+			target.Owner = s.workload(ev.Pod, workloads)
+			select {
+			case ch <- &Event{Type: t, Target: target}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StreamLogs follows logs of the container in the pod named target.  Events
+// are streamed as logs for the virtual target and the virtual container.
+func (s *Kubernetes) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	switch {
+	case target == Events:
+		return s.streamEvents(ctx, k8s.EventOptions{}, since)
+	case container == Events:
+		return s.streamEvents(ctx, k8s.EventOptions{Kind: "Pod", Name: target}, since)
+	}
+
+	opts := k8s.LogOptions{Timestamps: true, SinceTime: since}
+	logs, err := s.client.WatchLogs(ctx, s.namespace, target, container, opts)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for line := range logs {
+			select {
+			case ch <- LogLine{Time: line.Time, Text: line.Text}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// streamEvents follows events selected by opts, and sends them as log lines.
+// The events occurred before since are skipped if since is not zero.
+func (s *Kubernetes) streamEvents(ctx context.Context, opts k8s.EventOptions, since time.Time) (<-chan LogLine, error) {
+	events, err := s.client.WatchEvents(ctx, s.namespace, opts)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for ev := range events {
+			line := eventLine(ev)
+			if !since.IsZero() && line.Time.Before(since) {
+				continue
+			}
+			select {
+			case ch <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// eventLine formats the event as a log line, such as "[Warning] BackOff
+// Pod/nginx: Back-off restarting failed container (x5)".  The type is
+// bracketed so that warnings are detected as the level.
+func eventLine(ev *corev1.Event) LogLine {
+	text := fmt.Sprintf("[%s] %s %s/%s: %s", ev.Type, ev.Reason, ev.InvolvedObject.Kind, ev.InvolvedObject.Name, strings.TrimSpace(ev.Message))
+	if ev.Count > 1 {
+		text += fmt.Sprintf(" (x%d)", ev.Count)
+	}
+	return LogLine{Time: k8s.EventTime(ev), Text: text}
+}
+
+// workload returns the workload of the pod as "Kind/Name".  The workload is
+// cached by the controller of the pod to get the ReplicaSet only once.  The
+// controller is used if the workload is not resolved, such as the ReplicaSet
+// has been deleted.
+func (s *Kubernetes) workload(pod *corev1.Pod, cache map[string]string) string {
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil {
+		return ""
+	}
+	key := owner.Kind + "/" + owner.Name
+	if w, ok := cache[key]; ok {
+		return w
+	}
+	w, err := s.client.GetWorkload(pod)
+	if err != nil {
+		return key
+	}
+	cache[key] = w.String()
+	return cache[key]
+}
+
+func eventsTarget() *Target {
+	return &Target{
+		Name:       Events,
+		Status:     types.PodRunning,
+		Containers: []string{Events},
+		Virtual:    true,
+	}
+}
+
+func podTarget(pod *corev1.Pod) *Target {
+	return &Target{
+		Name:       pod.Name,
+		Status:     types.GetPodStatus(pod),
+		Containers: append(k8s.ContainerNames(pod), Events),
+		CreatedAt:  pod.CreationTimestamp.Time,
+	}
+}
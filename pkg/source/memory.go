@@ -0,0 +1,177 @@
+package source
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Memory is a Source of targets and logs in the memory.  Targets and lines
+// are added by the methods, and they are sent to the subscribers.  It is
+// useful to run the app without Kubernetes, such as in tests.
+type Memory struct {
+	mu     sync.Mutex
+	events []*Event
+	logs   map[containerKey][]LogLine
+	closed map[containerKey]bool
+
+	// notify is closed and replaced on each change
+	notify chan struct{}
+}
+
+// containerKey identifies the container in the target
+type containerKey struct {
+	target    string
+	container string
+}
+
+// NewMemory returns a new empty Memory
+func NewMemory() *Memory {
+	return &Memory{
+		logs:   make(map[containerKey][]LogLine),
+		closed: make(map[containerKey]bool),
+		notify: make(chan struct{}),
+	}
+}
+
+// AddTarget adds the target
+func (m *Memory) AddTarget(target Target) {
+	m.pushEvent(TargetAdded, target)
+}
+
+// UpdateTarget updates the target which has the same name
+func (m *Memory) UpdateTarget(target Target) {
+	m.pushEvent(TargetModified, target)
+}
+
+// DeleteTarget deletes the target by the name
+func (m *Memory) DeleteTarget(name string) {
+	m.pushEvent(TargetDeleted, Target{Name: name})
+}
+
+// AppendLogs appends lines to the logs of the container in the target
+func (m *Memory) AppendLogs(target, container string, lines ...LogLine) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := containerKey{target: target, container: container}
+	m.logs[key] = append(m.logs[key], lines...)
+	m.broadcast()
+}
+
+// CloseLogs ends the logs of the container in the target.  The subscribers
+// of the logs are closed after all lines are sent.
+func (m *Memory) CloseLogs(target, container string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed[containerKey{target: target, container: container}] = true
+	m.broadcast()
+}
+
+func (m *Memory) pushEvent(t EventType, target Target) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.events = append(m.events, &Event{Type: t, Target: &target})
+	m.broadcast()
+}
+
+// broadcast wakes up the subscribers.  It must be called with the lock.
+func (m *Memory) broadcast() {
+	close(m.notify)
+	m.notify = make(chan struct{})
+}
+
+// WatchTargets returns a channel to subscribe events of the targets from the
+// first one added
+func (m *Memory) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+		var i int
+		for {
+			m.mu.Lock()
+			events := m.events[i:]
+			notify := m.notify
+			m.mu.Unlock()
+
+			for _, ev := range events {
+				target := *ev.Target
+				select {
+				case ch <- &Event{Type: ev.Type, Target: &target}:
+				case <-ctx.Done():
+					return
+				}
+			}
+			i += len(events)
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StreamLogs follows lines of the container in the target.  Lines which have
+// timestamps before since are skipped.  It returns an error if no such
+// target is added.
+func (m *Memory) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	if !m.hasTarget(target) {
+		return nil, errors.Errorf("target %s not found", target)
+	}
+
+	key := containerKey{target: target, container: container}
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		var i int
+		for {
+			m.mu.Lock()
+			lines := m.logs[key][i:]
+			closed := m.closed[key]
+			notify := m.notify
+			m.mu.Unlock()
+
+			for _, line := range lines {
+				if !since.IsZero() && !line.Time.IsZero() && line.Time.Before(since) {
+					continue
+				}
+				select {
+				case ch <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			i += len(lines)
+			if closed {
+				return
+			}
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (m *Memory) hasTarget(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found bool
+	for _, ev := range m.events {
+		if ev.Target.Name == name {
+			found = ev.Type != TargetDeleted
+		}
+	}
+	return found
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func TestMemory(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := NewMemory()
+	m.AddTarget(Target{Name: "nginx", Status: types.PodPending, Containers: []string{"app"}})
+
+	events, err := m.WatchTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.UpdateTarget(Target{Name: "nginx", Status: types.PodRunning, Containers: []string{"app"}})
+	m.DeleteTarget("nginx")
+
+	expected := []struct {
+		t      EventType
+		status types.PodStatus
+	}{
+		{TargetAdded, types.PodPending},
+		{TargetModified, types.PodRunning},
+		{TargetDeleted, ""},
+	}
+	for _, e := range expected {
+		ev := <-events
+		if ev.Type != e.t || ev.Target.Name != "nginx" || ev.Target.Status != e.status {
+			t.Errorf("unexpected event: %v %+v", ev.Type, ev.Target)
+		}
+	}
+
+	if _, err := m.StreamLogs(ctx, "nginx", "app", time.Time{}); err == nil {
+		t.Error("expected error for deleted target")
+	}
+
+	m.AddTarget(Target{Name: "redis", Containers: []string{"redis"}})
+	base := time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)
+	m.AppendLogs("redis", "redis", LogLine{Time: base, Text: "old"}, LogLine{Time: base.Add(time.Minute), Text: "new"})
+
+	ch, err := m.StreamLogs(ctx, "redis", "redis", base.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.AppendLogs("redis", "redis", LogLine{Time: base.Add(2 * time.Minute), Text: "newer"})
+	m.CloseLogs("redis", "redis")
+
+	var lines []string
+	for line := range ch {
+		lines = append(lines, line.Text)
+	}
+	if strings.Join(lines, ",") != "new,newer" {
+		t.Errorf("unexpected lines: %q", lines)
+	}
+}
@@ -0,0 +1,193 @@
+package source
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Nodes is a Source of nodes in the cluster.  The services on the node, such
+// as kubelet, and the log files in /var/log of the node are shown as the
+// containers.  The logs of the services are queried by the node log query of
+// the kubelet, and the files are read via the kubelet proxy.
+type Nodes struct {
+	client   *k8s.Client
+	services []string
+}
+
+// NewNodes returns a new Nodes source of nodes with the names of the services
+// to query logs, such as "kubelet"
+func NewNodes(client *k8s.Client, services []string) *Nodes {
+	return &Nodes{
+		client:   client,
+		services: services,
+	}
+}
+
+// WatchTargets watches nodes and returns a channel to subscribe them as the
+// targets.  The node is added with the services, and the log files of the
+// node are added as the modification after they are listed.  The error on
+// listing the files is sent with the modification, and the node keeps only
+// the services.
+func (s *Nodes) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	events, err := s.client.WatchNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+
+		targets := make(map[string]*Target)
+		listed := make(chan nodeLogFiles)
+		for {
+			var e *Event
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				e = s.handleNodeEvent(ctx, ev, targets, listed)
+			case l := <-listed:
+				e = s.handleNodeLogFiles(l, targets)
+			case <-ctx.Done():
+				return
+			}
+			if e == nil {
+				continue
+			}
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// nodeLogFiles is the result of listing the log files of the node
+type nodeLogFiles struct {
+	node  string
+	files []string
+	err   error
+}
+
+// handleNodeEvent updates the targets by the event of the node, and returns
+// the event of the target.  It returns nil if the target is not changed.
+// The log files of the node added are listed in background, and sent to
+// listed.
+func (s *Nodes) handleNodeEvent(ctx context.Context, ev *k8s.NodeEvent, targets map[string]*Target, listed chan<- nodeLogFiles) *Event {
+	name := ev.Node.Name
+	old, ok := targets[name]
+	switch {
+	case ev.Type == k8s.NodeDeleted:
+		if !ok {
+			return nil
+		}
+		delete(targets, name)
+		return &Event{Type: TargetDeleted, Target: old}
+	case ok:
+		t := &Target{Name: name, Status: nodeStatus(ev.Node), Containers: old.Containers, CreatedAt: old.CreatedAt}
+		if reflect.DeepEqual(old, t) {
+			// skip the updates of the heartbeat
+			return nil
+		}
+		targets[name] = t
+		return &Event{Type: TargetModified, Target: t}
+	}
+
+	t := &Target{
+		Name:       name,
+		Status:     nodeStatus(ev.Node),
+		Containers: append([]string{}, s.services...),
+		CreatedAt:  ev.Node.CreationTimestamp.Time,
+	}
+	targets[name] = t
+	go func() {
+		files, err := s.client.ListNodeLogFiles(ctx, name)
+		select {
+		case listed <- nodeLogFiles{node: name, files: files, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+	return &Event{Type: TargetAdded, Target: t}
+}
+
+// handleNodeLogFiles adds the log files to the containers of the node, and
+// returns the modification of the target.  It returns nil if the node has
+// been deleted.
+func (s *Nodes) handleNodeLogFiles(l nodeLogFiles, targets map[string]*Target) *Event {
+	old, ok := targets[l.node]
+	if !ok {
+		return nil
+	}
+	if l.err != nil {
+		return &Event{Type: TargetModified, Target: old, Err: l.err}
+	}
+	t := *old
+	t.Containers = append(append([]string{}, s.services...), l.files...)
+	targets[l.node] = &t
+	return &Event{Type: TargetModified, Target: &t}
+}
+
+// StreamLogs follows logs of the service or the log file named container on
+// the node named target.  The logs of the files do not have timestamps, so
+// since is used only for the services.
+func (s *Nodes) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	var logs <-chan k8s.LogLine
+	var err error
+	if s.isService(container) {
+		logs, err = s.client.WatchNodeServiceLogs(ctx, target, container, since)
+	} else {
+		logs, err = s.client.WatchNodeLogFile(ctx, target, container)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan LogLine)
+	go func() {
+		defer close(ch)
+		for line := range logs {
+			select {
+			case ch <- LogLine{Time: line.Time, Text: line.Text}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+func (s *Nodes) isService(name string) bool {
+	for _, service := range s.services {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeStatus returns the status of the node by its Ready condition.  The
+// node cordoned is shown as pending.
+func nodeStatus(node *corev1.Node) types.PodStatus {
+	for _, c := range node.Status.Conditions {
+		if c.Type != corev1.NodeReady {
+			continue
+		}
+		switch c.Status {
+		case corev1.ConditionTrue:
+			if node.Spec.Unschedulable {
+				return types.PodPending
+			}
+			return types.PodRunning
+		case corev1.ConditionFalse:
+			return types.PodFailed
+		}
+	}
+	return types.PodUnknown
+}
@@ -0,0 +1,130 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// nodesClientset is a fake clientset which sends requests via the kubelet
+// proxy to the server
+type nodesClientset struct {
+	*fake.Clientset
+	proxy kubernetes.Interface
+}
+
+func (c *nodesClientset) CoreV1() corev1client.CoreV1Interface {
+	return &nodesCoreV1{CoreV1Interface: c.Clientset.CoreV1(), proxy: c.proxy.CoreV1().RESTClient()}
+}
+
+type nodesCoreV1 struct {
+	corev1client.CoreV1Interface
+	proxy rest.Interface
+}
+
+func (c *nodesCoreV1) RESTClient() rest.Interface {
+	return c.proxy
+}
+
+// serveNodeLogs lists the log files of node-1, and fails on other nodes
+func serveNodeLogs(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/api/v1/nodes/node-1/proxy/logs/" {
+		http.Error(w, "node is not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, `<pre><a href="syslog">syslog</a></pre>`)
+}
+
+func TestNodesWatchTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(serveNodeLogs))
+	defer server.Close()
+	proxy, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientset := fake.NewSimpleClientset()
+	client := k8s.NewClientForClientset(&nodesClientset{Clientset: clientset, proxy: proxy})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := NewNodes(client, []string{"kubelet"}).WatchTargets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive := func(typ EventType, name string, containers ...string) *Event {
+		t.Helper()
+		select {
+		case ev := <-ch:
+			if ev.Type != typ || ev.Target.Name != name || !reflect.DeepEqual(ev.Target.Containers, containers) {
+				t.Fatalf("unexpected event: %v %s %q", ev.Type, ev.Target.Name, ev.Target.Containers)
+			}
+			return ev
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out")
+			return nil
+		}
+	}
+
+	createNode := func(name string) {
+		if _, err := clientset.CoreV1().Nodes().Create(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the log files are added after they are listed
+	createNode("node-1")
+	receive(TargetAdded, "node-1", "kubelet")
+	receive(TargetModified, "node-1", "kubelet", "syslog")
+
+	// the error on listing the files is sent with the services
+	createNode("node-2")
+	receive(TargetAdded, "node-2", "kubelet")
+	ev := receive(TargetModified, "node-2", "kubelet")
+	if ev.Err == nil || !strings.Contains(ev.Err.Error(), "failed to list logs of node-2") {
+		t.Errorf("unexpected error: %v", ev.Err)
+	}
+}
+
+func TestNodeStatus(t *testing.T) {
+	node := func(ready corev1.ConditionStatus, unschedulable bool) *corev1.Node {
+		n := &corev1.Node{Spec: corev1.NodeSpec{Unschedulable: unschedulable}}
+		if len(ready) > 0 {
+			n.Status.Conditions = []corev1.NodeCondition{
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+				{Type: corev1.NodeReady, Status: ready},
+			}
+		}
+		return n
+	}
+
+	cases := []struct {
+		node     *corev1.Node
+		expected types.PodStatus
+	}{
+		{node: node(corev1.ConditionTrue, false), expected: types.PodRunning},
+		{node: node(corev1.ConditionTrue, true), expected: types.PodPending},
+		{node: node(corev1.ConditionFalse, false), expected: types.PodFailed},
+		{node: node(corev1.ConditionUnknown, false), expected: types.PodUnknown},
+		{node: node("", false), expected: types.PodUnknown},
+	}
+	for i, c := range cases {
+		if status := nodeStatus(c.node); status != c.expected {
+			t.Errorf("#%d: unexpected status: %s, want %s", i, status, c.expected)
+		}
+	}
+}
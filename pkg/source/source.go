@@ -0,0 +1,87 @@
+package source
+
+import (
+	"context"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+// Target is a source of logs shown in the list, such as a pod or a file.  A
+// target has one or more containers which provide logs.
+type Target struct {
+	Name       string
+	Status     types.PodStatus
+	Containers []string
+
+	// Owner identifies the workload controlling the target, such as
+	// "Deployment/nginx" for a pod.  A target deleted may be replaced by a
+	// new target of the same owner.  It is empty if the target has no owner.
+	Owner string
+
+	// CreatedAt is the time when the target is created.  It is zero if
+	// unknown.
+	CreatedAt time.Time
+
+	// Parent is the name of the target which the target is nested beneath,
+	// such as the workload of a pod.  It is empty for a top-level target.
+	Parent string
+
+	// Virtual is true if the target is not a pod but a stream provided by
+	// the source, such as events in the namespace.  It is not selected
+	// automatically.
+	Virtual bool
+}
+
+// EventType represents an event type of the target
+type EventType int
+
+// The event type of the targets
+const (
+	TargetAdded    EventType = iota // The target is added
+	TargetModified                  // The target is updated
+	TargetDeleted                   // The target is deleted
+)
+
+// Event represents an event of the targets
+type Event struct {
+	Type   EventType
+	Target *Target
+
+	// Err is the error on getting the target to be reported, such as
+	// failing to list the log files of the node.  The target is sent
+	// without the information.
+	Err error
+}
+
+// LogLine is a line of the container's log
+type LogLine struct {
+	// Time is the timestamp of the line.  It is zero if unknown.
+	Time time.Time
+
+	// Text is the content of the line without the timestamp
+	Text string
+}
+
+// TargetLister lists targets and watches their changes
+type TargetLister interface {
+	// WatchTargets returns a channel to subscribe events of the targets.
+	// The current targets are sent as added first.  The channel is closed
+	// when ctx is done.
+	WatchTargets(ctx context.Context) (<-chan *Event, error)
+}
+
+// LogStreamer streams logs of the containers in the targets
+type LogStreamer interface {
+	// StreamLogs returns a channel to subscribe log lines of the container
+	// in the target.  The logs since the time are sent if since is not zero.
+	// The channel is closed when the logs end or ctx is done.
+	StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error)
+}
+
+// Source provides targets and logs of their containers to the app, such as
+// Kubernetes, local files or the memory
+type Source interface {
+	TargetLister
+	LogStreamer
+}
@@ -0,0 +1,293 @@
+package source
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+// Workloads is a Source of workloads in the namespace, such as Deployments,
+// and their pods nested beneath them.  The logs of the workload are
+// aggregated from the container in all pods of the workload.  Pods not
+// controlled by the workloads are shown on the top level.
+type Workloads struct {
+	*Kubernetes
+}
+
+// NewWorkloads returns a new Workloads source of the workloads in namespace
+// and their pods selected by the label selector
+func NewWorkloads(client *k8s.Client, namespace, selector string) *Workloads {
+	return &Workloads{
+		Kubernetes: NewKubernetes(client, namespace, selector),
+	}
+}
+
+// WatchTargets watches workloads and pods, and returns a channel to
+// subscribe them as the targets.  The virtual target of events in the
+// namespace is sent first.
+func (s *Workloads) WatchTargets(ctx context.Context) (<-chan *Event, error) {
+	workloads, err := s.client.WatchWorkloads(ctx, s.namespace)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := s.client.WatchPods(ctx, s.namespace, k8s.PodOptions{LabelSelector: s.selector})
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan *Event)
+	go func() {
+		defer close(ch)
+
+		select {
+		case ch <- &Event{Type: TargetAdded, Target: eventsTarget()}:
+		case <-ctx.Done():
+			return
+		}
+
+		tree := newWorkloadTree()
+		cache := make(map[string]string)
+		for workloads != nil || pods != nil {
+			var events []*Event
+			select {
+			case ev, ok := <-workloads:
+				if !ok {
+					workloads = nil
+					continue
+				}
+				events = tree.updateWorkload(ev)
+			case ev, ok := <-pods:
+				if !ok {
+					pods = nil
+					continue
+				}
+				if ev.Type != k8s.PodDeleted && s.podFunc != nil {
+					s.podFunc(ev.Pod)
+				}
+				target := podTarget(ev.Pod)
+				target.Owner = s.workload(ev.Pod, cache)
+				events = tree.updatePod(target, ev.Type == k8s.PodDeleted)
+			}
+
+			// keep receiving until the channels are closed after ctx is done
+			for _, ev := range events {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StreamLogs follows logs of the container in the pod named target.  If the
+// target is a workload, logs of the container in all pods of the workload
+// are aggregated with the prefix of the pod name.  Events of the workload are
+// streamed for the virtual container.
+func (s *Workloads) StreamLogs(ctx context.Context, target, container string, since time.Time) (<-chan LogLine, error) {
+	// names of the workloads contain the kind, such as "Deployment/nginx"
+	i := strings.Index(target, "/")
+	if i < 0 {
+		return s.Kubernetes.StreamLogs(ctx, target, container, since)
+	}
+	if container == Events {
+		return s.streamEvents(ctx, k8s.EventOptions{Kind: target[:i], Name: target[i+1:]}, since)
+	}
+
+	events, err := s.client.WatchPods(ctx, s.namespace, k8s.PodOptions{LabelSelector: s.selector})
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan LogLine)
+	streams := s.client.NewLogStreams(ctx, since, func(ref k8s.ContainerRef, line k8s.LogLine) {
+		select {
+		case ch <- LogLine{Time: line.Time, Text: "[" + ref.Pod + "] " + line.Text}:
+		case <-ctx.Done():
+		}
+	})
+	go func() {
+		defer close(ch)
+		defer streams.Close()
+
+		cache := make(map[string]string)
+		filter := func(name string) bool { return name == container }
+		for ev := range events {
+			if ev.Type == k8s.PodDeleted || s.workload(ev.Pod, cache) != target {
+				continue
+			}
+			streams.Watch(ev.Pod, filter)
+		}
+	}()
+	return ch, nil
+}
+
+// workloadTree is a state of the workloads and the pods nested beneath them.
+// It converts events of the workloads and the pods into events of the
+// targets.  Targets sent in the events are never modified.
+type workloadTree struct {
+	workloads map[string]*Target
+	pods      map[string]*Target
+}
+
+func newWorkloadTree() *workloadTree {
+	return &workloadTree{
+		workloads: make(map[string]*Target),
+		pods:      make(map[string]*Target),
+	}
+}
+
+// updateWorkload updates the workload by the event, and returns events of
+// the targets.  The pods of the workload are moved beneath the workload on
+// added, and moved to the top level on deleted.
+func (t *workloadTree) updateWorkload(ev *k8s.WorkloadEvent) []*Event {
+	// pods of the Job are nested beneath the CronJob
+	if ev.Owner != nil && ev.Owner.Kind == "CronJob" {
+		return nil
+	}
+
+	name := ev.Workload.String()
+	old, ok := t.workloads[name]
+	if ev.Type == k8s.WorkloadDeleted {
+		if !ok {
+			return nil
+		}
+		delete(t.workloads, name)
+		events := t.movePods(name, "")
+		return append(events, &Event{Type: TargetDeleted, Target: old})
+	}
+
+	w := &Target{Name: name, Containers: append(ev.Containers, Events)}
+	w.Status = workloadStatus(t.children(name))
+	t.workloads[name] = w
+	if ok {
+		if reflect.DeepEqual(old, w) {
+			return nil
+		}
+		return []*Event{{Type: TargetModified, Target: w}}
+	}
+	events := []*Event{{Type: TargetAdded, Target: w}}
+	return append(events, t.movePods("", name)...)
+}
+
+// updatePod updates the pod, and returns events of the targets.  The status
+// of the workload is also updated by its pods.
+func (t *workloadTree) updatePod(pod *Target, deleted bool) []*Event {
+	old, ok := t.pods[pod.Name]
+	if deleted {
+		if !ok {
+			return nil
+		}
+		delete(t.pods, pod.Name)
+		events := []*Event{{Type: TargetDeleted, Target: old}}
+		return append(events, t.updateStatus(old.Parent)...)
+	}
+
+	if _, found := t.workloads[pod.Owner]; found {
+		pod.Parent = pod.Owner
+	}
+	t.pods[pod.Name] = pod
+
+	var events []*Event
+	if ok {
+		events = append(events, &Event{Type: TargetModified, Target: pod})
+		if old.Parent != pod.Parent {
+			events = append(events, t.updateStatus(old.Parent)...)
+		}
+	} else {
+		events = append(events, &Event{Type: TargetAdded, Target: pod})
+	}
+	return append(events, t.updateStatus(pod.Parent)...)
+}
+
+// movePods moves the pods of the workload named to from the parent, and
+// returns events of the pods modified
+func (t *workloadTree) movePods(from, to string) []*Event {
+	owner := from
+	if len(to) > 0 {
+		owner = to
+	}
+	var events []*Event
+	for _, p := range t.sortedPods() {
+		if p.Parent != from || p.Owner != owner {
+			continue
+		}
+		moved := *p
+		moved.Parent = to
+		t.pods[p.Name] = &moved
+		events = append(events, &Event{Type: TargetModified, Target: &moved})
+	}
+	return events
+}
+
+// updateStatus updates the status of the workload by its pods, and returns
+// an event if the status is changed
+func (t *workloadTree) updateStatus(name string) []*Event {
+	w, ok := t.workloads[name]
+	if !ok {
+		return nil
+	}
+	status := workloadStatus(t.children(name))
+	if status == w.Status {
+		return nil
+	}
+	updated := *w
+	updated.Status = status
+	t.workloads[name] = &updated
+	return []*Event{{Type: TargetModified, Target: &updated}}
+}
+
+// children returns the pods of the workload, which are nested beneath the
+// workload while it exists
+func (t *workloadTree) children(name string) []*Target {
+	var pods []*Target
+	for _, p := range t.sortedPods() {
+		if p.Owner == name {
+			pods = append(pods, p)
+		}
+	}
+	return pods
+}
+
+func (t *workloadTree) sortedPods() []*Target {
+	pods := make([]*Target, 0, len(t.pods))
+	for _, p := range t.pods {
+		pods = append(pods, p)
+	}
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[i].Name < pods[j].Name
+	})
+	return pods
+}
+
+// workloadStatus returns the status of the workload by the status of its
+// pods.  It is unknown if the workload has no pods, such as the Deployment
+// scaled to zero.
+func workloadStatus(pods []*Target) types.PodStatus {
+	if len(pods) == 0 {
+		return types.PodUnknown
+	}
+	var running, pending bool
+	for _, p := range pods {
+		switch p.Status {
+		case types.PodRunning:
+			running = true
+		case types.PodPending, types.PodInitializing, types.PodTerminating:
+			pending = true
+		case types.PodFailed, types.PodUnknown:
+			return types.PodFailed
+		}
+	}
+	switch {
+	case running:
+		return types.PodRunning
+	case pending:
+		return types.PodPending
+	}
+	return types.PodSucceeded
+}
@@ -0,0 +1,83 @@
+package source
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func workloadEvent(t k8s.WorkloadEventType, kind, name string) *k8s.WorkloadEvent {
+	return &k8s.WorkloadEvent{Type: t, Workload: k8s.Workload{Kind: kind, Name: name}, Containers: []string{"app"}}
+}
+
+// formatEvents returns the events as "type name parent status"
+func formatEvents(events []*Event) []string {
+	var s []string
+	for _, ev := range events {
+		s = append(s, []string{"added", "modified", "deleted"}[ev.Type]+" "+ev.Target.Name+" "+ev.Target.Parent+" "+string(ev.Target.Status))
+	}
+	return s
+}
+
+func TestWorkloadTree(t *testing.T) {
+	tree := newWorkloadTree()
+
+	cases := []struct {
+		events   []*Event
+		expected []string
+	}{
+		{
+			// the pod is shown on the top level until its workload is added
+			events:   tree.updatePod(&Target{Name: "web-abcde", Owner: "Deployment/web", Status: types.PodRunning}, false),
+			expected: []string{"added web-abcde  Running"},
+		},
+		{
+			events:   tree.updateWorkload(workloadEvent(k8s.WorkloadAdded, "Deployment", "web")),
+			expected: []string{"added Deployment/web  Running", "modified web-abcde Deployment/web Running"},
+		},
+		{
+			events:   tree.updatePod(&Target{Name: "web-fghij", Owner: "Deployment/web", Status: types.PodPending}, false),
+			expected: []string{"added web-fghij Deployment/web Pending"},
+		},
+		{
+			events:   tree.updatePod(&Target{Name: "web-abcde", Owner: "Deployment/web", Status: types.PodFailed}, false),
+			expected: []string{"modified web-abcde Deployment/web Failed", "modified Deployment/web  Failed"},
+		},
+		{
+			events:   tree.updatePod(&Target{Name: "web-abcde"}, true),
+			expected: []string{"deleted web-abcde Deployment/web Failed", "modified Deployment/web  Pending"},
+		},
+		{
+			// the Job is not shown, and its pods are nested beneath the CronJob
+			events: tree.updateWorkload(&k8s.WorkloadEvent{
+				Type:     k8s.WorkloadAdded,
+				Workload: k8s.Workload{Kind: "Job", Name: "backup-1562025600"},
+				Owner:    &k8s.Workload{Kind: "CronJob", Name: "backup"},
+			}),
+			expected: nil,
+		},
+		{
+			events:   tree.updateWorkload(workloadEvent(k8s.WorkloadAdded, "CronJob", "backup")),
+			expected: []string{"added CronJob/backup  Unknown"},
+		},
+		{
+			events:   tree.updatePod(&Target{Name: "backup-1562025600-xyz", Owner: "CronJob/backup", Status: types.PodSucceeded}, false),
+			expected: []string{"added backup-1562025600-xyz CronJob/backup Succeeded", "modified CronJob/backup  Succeeded"},
+		},
+		{
+			events:   tree.updateWorkload(workloadEvent(k8s.WorkloadModified, "Deployment", "web")),
+			expected: nil,
+		},
+		{
+			events:   tree.updateWorkload(workloadEvent(k8s.WorkloadDeleted, "Deployment", "web")),
+			expected: []string{"modified web-fghij  Pending", "deleted Deployment/web  Pending"},
+		},
+	}
+	for i, c := range cases {
+		if actual := formatEvents(c.events); !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("#%d: unexpected events: %q, want %q", i, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package timestamp
+
+import (
+	"strings"
+	"time"
+)
+
+// Split splits the line prefixed by the RFC3339 timestamp into the time and
+// the text, such as lines of logs with timestamps from Kubernetes and Docker
+// Engine.  It returns zero time and the line as it is if the line has no
+// timestamp.
+func Split(line string) (time.Time, string) {
+	i := strings.IndexByte(line, ' ')
+	if i == -1 {
+		i = len(line)
+	}
+	t, err := time.Parse(time.RFC3339Nano, line[:i])
+	if err != nil {
+		return time.Time{}, line
+	}
+	if i < len(line) {
+		i++
+	}
+	return t, line[i:]
+}
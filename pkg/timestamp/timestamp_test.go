@@ -0,0 +1,36 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		input string
+		time  time.Time
+		text  string
+	}{
+		{
+			input: "2019-07-01T12:00:00.123456789Z hello world",
+			time:  time.Date(2019, 7, 1, 12, 0, 0, 123456789, time.UTC),
+			text:  "hello world",
+		},
+		{
+			input: "2019-07-01T12:00:00Z",
+			time:  time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC),
+			text:  "",
+		},
+		{
+			input: "no timestamp",
+			text:  "no timestamp",
+		},
+	}
+
+	for _, c := range cases {
+		tm, text := Split(c.input)
+		if !tm.Equal(c.time) || text != c.text {
+			t.Errorf("Split(%q) = (%v, %q), want (%v, %q)", c.input, tm, text, c.time, c.text)
+		}
+	}
+}
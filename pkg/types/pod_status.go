@@ -41,7 +41,6 @@ func GetPodStatus(pod *corev1.Pod) PodStatus {
 		default:
 			return PodInitializing
 		}
-		break
 	}
 
 	hasCompleted := false
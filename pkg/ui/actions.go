@@ -0,0 +1,149 @@
+package ui
+
+// action is an operation of the UI bound to the keys.  The count is the
+// number typed before the keys, such as 10 of "10j", or 0 if it is not typed.
+type action func(ui *UI, count int)
+
+// repeat returns an action invoking f count times, or once if the count is
+// not typed
+func repeat(f func(ui *UI)) action {
+	return func(ui *UI, count int) {
+		for i := 0; i < countOrOne(count); i++ {
+			f(ui)
+		}
+	}
+}
+
+// once returns an action invoking f ignoring the count
+func once(f func(ui *UI)) action {
+	return func(ui *UI, count int) {
+		f(ui)
+	}
+}
+
+// prefix returns an action waiting for the letter following the keys, such
+// as "a" of "ma".  The key is consumed even if it is not a letter.
+func prefix(f func(ui *UI, name rune)) action {
+	return func(ui *UI, count int) {
+		ui.pending = f
+	}
+}
+
+// actions is the actions available in the modes by their names
+var actions = map[Mode]map[string]action{
+	ModeNormal: {
+		"quit":       once(func(ui *UI) { ui.listener.OnQuit() }),
+		"find":       once((*UI).enterFindInputMode),
+		"find-next":  repeat((*UI).findNext),
+		"find-prev":  repeat((*UI).findPrev),
+		"go-to-time": once((*UI).enterTimeInputMode),
+		"save":       once((*UI).enterSaveInputMode),
+		"pipe":       once((*UI).enterPipeInputMode),
+		"open-editor": once(func(ui *UI) {
+			ui.listener.OnEditorRequested(ui.targetLines())
+		}),
+		"open-pager": once(func(ui *UI) {
+			ui.listener.OnPagerRequested(ui.targetLines())
+		}),
+		"visual":          once((*UI).enterVisualMode),
+		"set-mark":        prefix((*UI).setMark),
+		"jump-to-mark":    prefix((*UI).jumpToMark),
+		"toggle-bookmark": once((*UI).toggleBookmark),
+		"next-bookmark":   repeat((*UI).nextBookmark),
+		"prev-bookmark":   repeat((*UI).prevBookmark),
+		"bookmarks":       once((*UI).openBookmarksPopup),
+		"next-pod": repeat(func(ui *UI) {
+			ui.pods.SelectNext()
+			ui.pager.SetKeyword("")
+		}),
+		"prev-pod": repeat(func(ui *UI) {
+			ui.pods.SelectPrev()
+			ui.pager.SetKeyword("")
+		}),
+		"switch-list":    once(func(ui *UI) { ui.listener.OnSwitchListRequested() }),
+		"next-container": repeat(func(ui *UI) { ui.containers.SelectNext() }),
+		"prev-container": repeat(func(ui *UI) { ui.containers.SelectPrev() }),
+		"toggle-follow":  once((*UI).toggleFollowMode),
+		"follow-workload": once(func(ui *UI) {
+			ui.listener.OnFollowWorkloadRequested()
+		}),
+		"toggle-ansi": once(func(ui *UI) {
+			ui.pager.SetStripANSI(!ui.pager.StripANSI())
+		}),
+		"toggle-json":         once(func(ui *UI) { ui.SetJSONMode(!ui.jsonMode) }),
+		"cycle-level":         once((*UI).cycleMinLevel),
+		"toggle-wrap":         once((*UI).toggleWrap),
+		"toggle-line-numbers": once((*UI).toggleGutter),
+		"cycle-timestamps":    once((*UI).cycleTimestampFormat),
+		"open-json":           once((*UI).openJSONPopup),
+		"scroll-down":         repeat((*UI).scrollDown),
+		"scroll-up":           repeat((*UI).scrollUp),
+		"scroll-left":         repeat((*UI).scrollHalfPageLeft),
+		"scroll-right":        repeat((*UI).scrollHaftPageRight),
+		"scroll-top":          (*UI).scrollToTopOrLine,
+		"scroll-bottom":       (*UI).scrollToBottomOrLine,
+		"half-page-down":      repeat((*UI).scrollHalfPageDown),
+		"half-page-up":        repeat((*UI).scrollHalfPageUp),
+		"page-down":           repeat((*UI).scrollPageDown),
+		"page-up":             repeat((*UI).scrollPageUp),
+	},
+	ModeVisual: {
+		"quit":   once(func(ui *UI) { ui.listener.OnQuit() }),
+		"cancel": once((*UI).exitVisualMode),
+		"visual": once((*UI).exitVisualMode),
+		"copy":   once((*UI).copySelection),
+		"pipe":   once((*UI).enterPipeInputMode),
+		"open-editor": once(func(ui *UI) {
+			ui.listener.OnEditorRequested(ui.targetLines())
+		}),
+		"open-pager": once(func(ui *UI) {
+			ui.listener.OnPagerRequested(ui.targetLines())
+		}),
+		"scroll-down": func(ui *UI, count int) { ui.moveSelection(countOrOne(count)) },
+		"scroll-up":   func(ui *UI, count int) { ui.moveSelection(-countOrOne(count)) },
+		"scroll-top":  once(func(ui *UI) { ui.moveSelection(-ui.pager.LineCount()) }),
+		"scroll-bottom": once(func(ui *UI) {
+			ui.moveSelection(ui.pager.LineCount())
+		}),
+	},
+	ModePopup: {
+		"quit":           once(func(ui *UI) { ui.listener.OnQuit() }),
+		"cancel":         once((*UI).closePopup),
+		"select":         once((*UI).selectPopupItem),
+		"scroll-down":    func(ui *UI, count int) { ui.scrollPopup(countOrOne(count)) },
+		"scroll-up":      func(ui *UI, count int) { ui.scrollPopup(-countOrOne(count)) },
+		"scroll-top":     once(func(ui *UI) { ui.scrollPopupToEdge(false) }),
+		"scroll-bottom":  once(func(ui *UI) { ui.scrollPopupToEdge(true) }),
+		"scroll-left":    repeat(func(ui *UI) { ui.popup.Pager().ScrollHalfPageLeft() }),
+		"scroll-right":   repeat(func(ui *UI) { ui.popup.Pager().ScrollHalfPageRight() }),
+		"half-page-down": repeat(func(ui *UI) { ui.popup.Pager().ScrollHalfPageDown() }),
+		"half-page-up":   repeat(func(ui *UI) { ui.popup.Pager().ScrollHalfPageUp() }),
+	},
+}
+
+// followActions is the actions in normal mode which are available in follow
+// mode
+var followActions = map[string]bool{
+	"quit":                true,
+	"next-pod":            true,
+	"prev-pod":            true,
+	"switch-list":         true,
+	"next-container":      true,
+	"prev-container":      true,
+	"toggle-follow":       true,
+	"follow-workload":     true,
+	"toggle-ansi":         true,
+	"toggle-json":         true,
+	"cycle-level":         true,
+	"toggle-wrap":         true,
+	"toggle-line-numbers": true,
+	"cycle-timestamps":    true,
+}
+
+// countOrOne returns the count, or 1 if the count is not typed
+func countOrOne(count int) int {
+	if count == 0 {
+		return 1
+	}
+	return count
+}
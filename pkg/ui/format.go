@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/jsonlog"
+	"github.com/ueokande/logbook/pkg/level"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+var (
+	styleJSONTime    = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	styleJSONMessage = tcell.StyleDefault.Bold(true)
+	styleJSONKey     = tcell.StyleDefault.Foreground(tcell.ColorTeal)
+
+	styleLevelError = tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+	styleLevelWarn  = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+	styleLevelInfo  = tcell.StyleDefault.Foreground(tcell.ColorGreen).Bold(true)
+	styleLevelDebug = tcell.StyleDefault.Foreground(tcell.ColorGray).Bold(true)
+)
+
+type segment struct {
+	text  string
+	style tcell.Style
+}
+
+// jsonFormatter returns a LineFormatter which renders JSON logs as "time
+// level msg key=value ...".  Lines which are not JSON are formatted by
+// widgets.FormatANSI.
+func jsonFormatter(fields jsonlog.Fields) widgets.LineFormatter {
+	return func(line string) ([]rune, []tcell.Style) {
+		e, ok := jsonlog.Parse(line, fields)
+		if !ok {
+			return widgets.FormatANSI(line)
+		}
+
+		var segs [][]segment
+		if len(e.Time) > 0 {
+			segs = append(segs, []segment{{e.Time, styleJSONTime}})
+		}
+		if len(e.Level) > 0 {
+			segs = append(segs, []segment{{e.Level, levelStyle(e.Level)}})
+		}
+		if len(e.Message) > 0 {
+			segs = append(segs, []segment{{e.Message, styleJSONMessage}})
+		}
+		for _, a := range e.Attrs {
+			segs = append(segs, []segment{
+				{a.Key + "=", styleJSONKey},
+				{a.QuotedValue(), tcell.StyleDefault},
+			})
+		}
+
+		var runes []rune
+		var styles []tcell.Style
+		for i, seg := range segs {
+			if i > 0 {
+				runes = append(runes, ' ')
+				styles = append(styles, tcell.StyleDefault)
+			}
+			for _, s := range seg {
+				for _, c := range s.text {
+					runes = append(runes, c)
+					styles = append(styles, s.style)
+				}
+			}
+		}
+		return runes, styles
+	}
+}
+
+func levelStyle(name string) tcell.Style {
+	switch level.Parse(name) {
+	case level.Error:
+		return styleLevelError
+	case level.Warn:
+		return styleLevelWarn
+	case level.Info:
+		return styleLevelInfo
+	case level.Debug, level.Trace:
+		return styleLevelDebug
+	}
+	return tcell.StyleDefault
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// testListener records events of the UI
+type testListener struct {
+	pods       []string
+	containers []string
+	quit       bool
+	copied     []widgets.Line
+
+	nopListener
+}
+
+func (l *testListener) OnPodSelected(name string, index int) {
+	l.pods = append(l.pods, name)
+}
+
+func (l *testListener) OnContainerSelected(name string, index int) {
+	l.containers = append(l.containers, name)
+}
+
+func (l *testListener) OnCopyRequested(lines []widgets.Line) {
+	l.copied = lines
+}
+
+func (l *testListener) OnQuit() {
+	l.quit = true
+}
+
+// harness runs the UI on a simulation screen without a terminal
+type harness struct {
+	t        *testing.T
+	screen   tcell.SimulationScreen
+	ui       *UI
+	listener *testListener
+}
+
+func newHarness(t *testing.T, width, height int) *harness {
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatal(err)
+	}
+	screen.SetSize(width, height)
+
+	l := &testListener{}
+	ui := NewUI()
+	ui.SetStatusMode(ModeNormal)
+	ui.WatchUIEvents(l)
+	ui.SetView(screen)
+	ui.Resize()
+
+	return &harness{t: t, screen: screen, ui: ui, listener: l}
+}
+
+// key sends the key to the UI
+func (h *harness) key(key tcell.Key) {
+	h.ui.HandleEvent(tcell.NewEventKey(key, 0, tcell.ModNone))
+}
+
+// typeText sends the runes in the text to the UI
+func (h *harness) typeText(text string) {
+	for _, r := range text {
+		h.ui.HandleEvent(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+}
+
+// rows draws the UI and returns rows on the screen without trailing spaces
+func (h *harness) rows() []string {
+	h.ui.Resize()
+	h.ui.Draw()
+	h.screen.Show()
+
+	cells, width, height := h.screen.GetContents()
+	rows := make([]string, height)
+	for y := 0; y < height; y++ {
+		var b strings.Builder
+		for _, c := range cells[y*width : (y+1)*width] {
+			if len(c.Runes) == 0 {
+				b.WriteByte(' ')
+				continue
+			}
+			b.WriteRune(c.Runes[0])
+		}
+		rows[y] = strings.TrimRight(b.String(), " ")
+	}
+	return rows
+}
+
+// row returns the row at y.  The negative y is the row from the bottom.
+func (h *harness) row(y int) string {
+	rows := h.rows()
+	if y < 0 {
+		y += len(rows)
+	}
+	return rows[y]
+}
+
+// findRow returns the index of the first row containing the text.  It
+// returns -1 if not found.
+func (h *harness) findRow(text string) int {
+	for y, row := range h.rows() {
+		if strings.Contains(row, text) {
+			return y
+		}
+	}
+	return -1
+}
+
+// style returns the style of the cell at x and y on the screen drawn last
+func (h *harness) style(x, y int) tcell.Style {
+	_, _, style, _ := h.screen.GetContent(x, y)
+	return style
+}
+
+// expectRow fails the test if the row at y does not contain the text
+func (h *harness) expectRow(y int, text string) {
+	h.t.Helper()
+	if row := h.row(y); !strings.Contains(row, text) {
+		h.t.Errorf("row %d does not contain %q:\n%s", y, text, strings.Join(h.rows(), "\n"))
+	}
+}
@@ -0,0 +1,348 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+)
+
+// keyStroke is a key identified by the key code, and the rune for KeyRune
+type keyStroke struct {
+	key tcell.Key
+	ch  rune
+}
+
+func runeKey(ch rune) keyStroke {
+	return keyStroke{key: tcell.KeyRune, ch: ch}
+}
+
+func codeKey(key tcell.Key) keyStroke {
+	return keyStroke{key: key}
+}
+
+func keyStrokeOf(ev *tcell.EventKey) keyStroke {
+	if ev.Key() == tcell.KeyRune {
+		return runeKey(ev.Rune())
+	}
+	return codeKey(ev.Key())
+}
+
+// isDigit returns true if the key is a digit of the count
+func (k keyStroke) isDigit() bool {
+	return k.key == tcell.KeyRune && k.ch >= '0' && k.ch <= '9'
+}
+
+// unbound is the action to unbind the key from the default action
+const unbound = "none"
+
+// modeNames is the modes having the keymap by their names.  Follow mode
+// uses the keymap of normal mode.
+var modeNames = map[string]Mode{
+	"normal": ModeNormal,
+	"visual": ModeVisual,
+	"popup":  ModePopup,
+}
+
+// defaultBindings is the actions bound to the keys by default in the modes
+var defaultBindings = map[Mode]map[string]string{
+	ModeNormal: {
+		"q":       "quit",
+		"ctrl-c":  "quit",
+		"/":       "find",
+		"n":       "find-next",
+		"N":       "find-prev",
+		"@":       "go-to-time",
+		"s":       "save",
+		"|":       "pipe",
+		"v":       "open-editor",
+		"P":       "open-pager",
+		"V":       "visual",
+		"m":       "set-mark",
+		"'":       "jump-to-mark",
+		"b":       "toggle-bookmark",
+		"]":       "next-bookmark",
+		"[":       "prev-bookmark",
+		"B":       "bookmarks",
+		"ctrl-n":  "next-pod",
+		"ctrl-p":  "prev-pod",
+		"ctrl-w":  "switch-list",
+		"tab":     "next-container",
+		"backtab": "prev-container",
+		"f":       "toggle-follow",
+		"W":       "follow-workload",
+		"c":       "toggle-ansi",
+		"J":       "toggle-json",
+		"L":       "cycle-level",
+		"w":       "toggle-wrap",
+		"#":       "toggle-line-numbers",
+		"T":       "cycle-timestamps",
+		"enter":   "open-json",
+		"j":       "scroll-down",
+		"down":    "scroll-down",
+		"k":       "scroll-up",
+		"up":      "scroll-up",
+		"h":       "scroll-left",
+		"left":    "scroll-left",
+		"l":       "scroll-right",
+		"right":   "scroll-right",
+		"g":       "scroll-top",
+		"G":       "scroll-bottom",
+		"ctrl-d":  "half-page-down",
+		"ctrl-u":  "half-page-up",
+		"ctrl-f":  "page-down",
+		"ctrl-b":  "page-up",
+	},
+	ModeVisual: {
+		"ctrl-c": "quit",
+		"esc":    "cancel",
+		"q":      "cancel",
+		"V":      "visual",
+		"y":      "copy",
+		"enter":  "copy",
+		"|":      "pipe",
+		"v":      "open-editor",
+		"P":      "open-pager",
+		"j":      "scroll-down",
+		"down":   "scroll-down",
+		"k":      "scroll-up",
+		"up":     "scroll-up",
+		"g":      "scroll-top",
+		"G":      "scroll-bottom",
+	},
+	ModePopup: {
+		"ctrl-c": "quit",
+		"esc":    "cancel",
+		"q":      "cancel",
+		"enter":  "select",
+		"j":      "scroll-down",
+		"down":   "scroll-down",
+		"k":      "scroll-up",
+		"up":     "scroll-up",
+		"g":      "scroll-top",
+		"G":      "scroll-bottom",
+		"h":      "scroll-left",
+		"l":      "scroll-right",
+		"ctrl-d": "half-page-down",
+		"ctrl-u": "half-page-up",
+	},
+}
+
+// keyNames is the keys by their lower-cased names, such as "ctrl-n" or
+// "backtab"
+var keyNames = func() map[string]tcell.Key {
+	names := make(map[string]tcell.Key)
+	for k, name := range tcell.KeyNames {
+		names[strings.ToLower(name)] = k
+	}
+	return names
+}()
+
+// parseKey parses the name of the key, such as "x", "space", "enter" or
+// "ctrl-n"
+func parseKey(name string) (keyStroke, error) {
+	if utf8.RuneCountInString(name) == 1 {
+		r, _ := utf8.DecodeRuneInString(name)
+		return runeKey(r), nil
+	}
+	normalized := strings.Replace(strings.ToLower(name), "+", "-", -1)
+	if normalized == "space" {
+		return runeKey(' '), nil
+	}
+	if k, ok := keyNames[normalized]; ok {
+		return codeKey(k), nil
+	}
+	if normalized == "true" || normalized == "false" {
+		// YAML reads y and n as booleans
+		return keyStroke{}, errors.Errorf("unknown key %q, quote the keys such as \"y\" and \"n\"", name)
+	}
+	return keyStroke{}, errors.Errorf("unknown key %q", name)
+}
+
+// parseKeys parses the sequence of the keys separated by spaces, such as
+// "ctrl-w j".  The word which is not a name of the key is a sequence of the
+// characters, such as "gg".
+func parseKeys(sequence string) ([]keyStroke, error) {
+	var keys []keyStroke
+	for _, word := range strings.Fields(sequence) {
+		k, err := parseKey(word)
+		if err == nil {
+			keys = append(keys, k)
+			continue
+		}
+		if strings.ContainsAny(word, "-+") || word == "true" || word == "false" {
+			return nil, err
+		}
+		for _, r := range word {
+			keys = append(keys, runeKey(r))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.Errorf("empty key %q", sequence)
+	}
+	return keys, nil
+}
+
+// hasPrefix returns true if the keys start with the prefix
+func hasPrefix(keys, prefix []keyStroke) bool {
+	if len(keys) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if keys[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// keyNode is a node of the tree of the key sequences.  The leaf has the
+// action bound to the sequence from the root.
+type keyNode struct {
+	action string
+	next   map[keyStroke]*keyNode
+}
+
+// Keymap is the actions bound to the key sequences in the modes
+type Keymap struct {
+	roots map[Mode]*keyNode
+}
+
+// DefaultKeymap returns the Keymap of the default keys
+func DefaultKeymap() *Keymap {
+	m, err := ParseKeybindings(nil)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// ParseKeybindings returns a Keymap by the actions bound to the keys in the
+// modes, such as {"normal": {"ctrl-e": "scroll-down", "g": "none", "gg":
+// "scroll-top"}}.  The keys are bound in addition to the default keys of the
+// actions, and the keys bound to "none" are unbound.  Unknown modes, actions
+// and keys, and the conflicting keys are reported as errors.
+func ParseKeybindings(bindings map[string]map[string]string) (*Keymap, error) {
+	names := make([]string, 0, len(modeNames))
+	for name := range modeNames {
+		names = append(names, name)
+	}
+	for name := range bindings {
+		if _, ok := modeNames[name]; !ok {
+			return nil, errors.Errorf("unknown mode %q", name)
+		}
+	}
+	sort.Strings(names)
+
+	m := &Keymap{roots: make(map[Mode]*keyNode)}
+	for _, name := range names {
+		mode := modeNames[name]
+		root, err := buildKeyTree(mode, bindings[name])
+		if err != nil {
+			return nil, errors.Wrap(err, name)
+		}
+		m.roots[mode] = root
+	}
+	return m, nil
+}
+
+// binding is an action bound to the key sequence
+type binding struct {
+	sequence string
+	keys     []keyStroke
+	action   string
+}
+
+// buildKeyTree returns the tree of the default keys of the mode with the
+// user's bindings
+func buildKeyTree(mode Mode, user map[string]string) (*keyNode, error) {
+	var bindings []binding
+	for _, sequence := range sortedKeys(defaultBindings[mode]) {
+		action := defaultBindings[mode][sequence]
+		keys, _ := parseKeys(sequence)
+		bindings = append(bindings, binding{sequence: sequence, keys: keys, action: action})
+	}
+
+	var added []binding
+	for _, sequence := range sortedKeys(user) {
+		action := user[sequence]
+		if _, ok := actions[mode][action]; !ok && action != unbound {
+			return nil, errors.Errorf("unknown action %q", action)
+		}
+		keys, err := parseKeys(sequence)
+		if err != nil {
+			return nil, err
+		}
+		if keys[0].isDigit() {
+			return nil, errors.Errorf("key %q starts with a digit, which is typed as a count", sequence)
+		}
+		if action == unbound {
+			bindings = removeBinding(bindings, keys, "")
+			continue
+		}
+		// the key bound to the same action by default is not a conflict
+		bindings = removeBinding(bindings, keys, action)
+		added = append(added, binding{sequence: sequence, keys: keys, action: action})
+	}
+
+	// the user's bindings are checked after the keys are unbound
+	for _, b := range added {
+		for _, other := range bindings {
+			if hasPrefix(b.keys, other.keys) || hasPrefix(other.keys, b.keys) {
+				return nil, errors.Errorf("key %q of %s conflicts with %q of %s", b.sequence, b.action, other.sequence, other.action)
+			}
+		}
+		bindings = append(bindings, b)
+	}
+
+	root := &keyNode{next: make(map[keyStroke]*keyNode)}
+	for _, b := range bindings {
+		node := root
+		for _, k := range b.keys {
+			child, ok := node.next[k]
+			if !ok {
+				child = &keyNode{next: make(map[keyStroke]*keyNode)}
+				node.next[k] = child
+			}
+			node = child
+		}
+		node.action = b.action
+	}
+	return root, nil
+}
+
+// removeBinding removes the binding of the keys.  It removes the binding
+// only if it is bound to the action unless the action is empty.
+func removeBinding(bindings []binding, keys []keyStroke, action string) []binding {
+	var rest []binding
+	for _, b := range bindings {
+		if len(b.keys) != len(keys) || !hasPrefix(b.keys, keys) || (action != "" && b.action != action) {
+			rest = append(rest, b)
+		}
+	}
+	return rest
+}
+
+// lookup returns the node of the key sequence in the mode, or nil if no
+// actions are bound to the sequence
+func (m *Keymap) lookup(mode Mode, keys []keyStroke) *keyNode {
+	node := m.roots[mode]
+	for _, k := range keys {
+		if node == nil {
+			return nil
+		}
+		node = node.next[k]
+	}
+	return node
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
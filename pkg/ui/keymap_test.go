@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := []struct {
+		sequence string
+		expected []keyStroke
+	}{
+		{"x", []keyStroke{runeKey('x')}},
+		{"#", []keyStroke{runeKey('#')}},
+		{"space", []keyStroke{runeKey(' ')}},
+		{"ctrl-e", []keyStroke{codeKey(tcell.KeyCtrlE)}},
+		{"Ctrl+E", []keyStroke{codeKey(tcell.KeyCtrlE)}},
+		{"backtab", []keyStroke{codeKey(tcell.KeyBacktab)}},
+		{"gg", []keyStroke{runeKey('g'), runeKey('g')}},
+		{"ctrl-w j", []keyStroke{codeKey(tcell.KeyCtrlW), runeKey('j')}},
+	}
+	for _, c := range cases {
+		keys, err := parseKeys(c.sequence)
+		if err != nil {
+			t.Errorf("parseKeys(%q): %v", c.sequence, err)
+		} else if !reflect.DeepEqual(keys, c.expected) {
+			t.Errorf("parseKeys(%q) = %v, want %v", c.sequence, keys, c.expected)
+		}
+	}
+
+	for _, sequence := range []string{"ctl-e", "", "false"} {
+		if _, err := parseKeys(sequence); err == nil {
+			t.Errorf("expected error for %q", sequence)
+		}
+	}
+}
+
+func TestParseKeybindingsInvalid(t *testing.T) {
+	cases := []struct {
+		bindings map[string]map[string]string
+		message  string
+	}{
+		{map[string]map[string]string{"insert": {}}, `unknown mode "insert"`},
+		{map[string]map[string]string{"normal": {"x": "scrol-down"}}, `normal: unknown action "scrol-down"`},
+		{map[string]map[string]string{"popup": {"x": "find"}}, `popup: unknown action "find"`},
+		{map[string]map[string]string{"normal": {"ctl-e": "scroll-down"}}, `unknown key "ctl-e"`},
+		{map[string]map[string]string{"normal": {"n": "scroll-down"}}, `key "n" of scroll-down conflicts with "n" of find-next`},
+		{map[string]map[string]string{"normal": {"gg": "scroll-bottom"}}, `key "gg" of scroll-bottom conflicts with "g" of scroll-top`},
+		{map[string]map[string]string{"normal": {"gg": "scroll-top"}}, `key "gg" of scroll-top conflicts with "g" of scroll-top`},
+		{map[string]map[string]string{"normal": {"x": "scroll-down", "xy": "scroll-up"}}, `key "xy" of scroll-up conflicts with "x" of scroll-down`},
+		{map[string]map[string]string{"normal": {"1x": "scroll-down"}}, "starts with a digit"},
+	}
+	for _, c := range cases {
+		_, err := ParseKeybindings(c.bindings)
+		if err == nil || !strings.Contains(err.Error(), c.message) {
+			t.Errorf("unexpected error for %v: %v", c.bindings, err)
+		}
+	}
+}
+
+func TestUIKeymap(t *testing.T) {
+	keymap, err := ParseKeybindings(map[string]map[string]string{
+		"normal": {
+			"ctrl-e": "scroll-down",
+			"ctrl-y": "scroll-up",
+			"g":      "none",
+			"gg":     "scroll-top",
+			"j":      "scroll-down",
+			"Q":      "quit",
+			"q":      "none",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarnessWithLines(t, 30)
+	h.ui.SetKeymap(keymap)
+
+	h.key(tcell.KeyCtrlE)
+	h.key(tcell.KeyCtrlE)
+	h.expectRow(1, "│line 3")
+	h.key(tcell.KeyCtrlY)
+	h.expectRow(1, "│line 2")
+
+	// the default keys of the actions are kept
+	h.typeText("j")
+	h.expectRow(1, "│line 3")
+	h.key(tcell.KeyDown)
+	h.expectRow(1, "│line 4")
+	h.typeText("q")
+	if h.listener.quit {
+		t.Error("quit by the unbound key")
+	}
+
+	h.typeText("G")
+	h.typeText("g")
+	h.expectRow(-2, "│line 30")
+	h.typeText("g")
+	h.expectRow(1, "│line 1")
+
+	// the keys are not bound in the input
+	h.typeText("/gg")
+	h.expectRow(-1, "/gg")
+	h.key(tcell.KeyEscape)
+
+	h.typeText("Q")
+	if !h.listener.quit {
+		t.Error("quit is not requested")
+	}
+}
+
+func TestUIKeySequenceReplay(t *testing.T) {
+	keymap, err := ParseKeybindings(map[string]map[string]string{
+		"normal": {"g": "none", "gg": "scroll-top"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newHarnessWithLines(t, 30)
+	h.ui.SetKeymap(keymap)
+
+	// the key not following the prefix is typed with the count
+	h.typeText("3gj")
+	h.expectRow(1, "│line 4")
+	h.typeText("g2j")
+	h.expectRow(1, "│line 6")
+	h.typeText("gg")
+	h.expectRow(1, "│line 1")
+}
+
+func TestUICount(t *testing.T) {
+	h := newHarnessWithLines(t, 30)
+
+	h.typeText("10j")
+	h.expectRow(1, "│line 11")
+	h.typeText("2k")
+	h.expectRow(1, "│line 9")
+
+	// the count of scroll-top is the line number
+	h.typeText("20g")
+	h.expectRow(1, "│line 20")
+	h.typeText("g")
+	h.expectRow(1, "│line 1")
+
+	// the count is cleared by the unbound key
+	h.typeText("5")
+	h.key(tcell.KeyEscape)
+	h.typeText("j")
+	h.expectRow(1, "│line 2")
+
+	// the count is available in visual mode
+	h.typeText("V3j")
+	h.key(tcell.KeyEnter)
+	h.expectRow(-1, "NORMAL")
+	if len(h.listener.copied) != 4 {
+		t.Errorf("unexpected copied lines: %d", len(h.listener.copied))
+	}
+}
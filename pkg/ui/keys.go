@@ -5,37 +5,79 @@ import (
 )
 
 func (ui *UI) handleEventKey(ev *tcell.EventKey) bool {
-	var handles []func(ev *tcell.EventKey) bool
+	ui.statusbar.SetMessage("")
+
 	switch ui.mode {
-	case ModeNormal:
-		handles = []func(ev *tcell.EventKey) bool{
-			ui.handleKeyInputFind,
-			ui.handleKeySelectContainer,
-			ui.handleKeyToggleFollowMode,
-			ui.handleKeyScroll,
-			ui.handleKeyFind,
-			ui.handleKeyQuit,
-		}
-	case ModeFollow:
-		handles = []func(ev *tcell.EventKey) bool{
-			ui.handleKeySelectContainer,
-			ui.handleKeyToggleFollowMode,
-			ui.handleKeyQuit,
-		}
+	case ModeNormal, ModeFollow, ModeVisual, ModePopup:
+		return ui.handleKeyAction(ev)
 	case ModeInputFind:
-		handles = []func(ev *tcell.EventKey) bool{
-			ui.handleEventKeyInput,
-			ui.handleKeyQuit,
-		}
+		return ui.handleEventKeyInput(ev) || ui.handleKeyQuit(ev)
+	case ModeInputTime:
+		return ui.handleEventKeyInputTime(ev) || ui.handleKeyQuit(ev)
+	case ModeInputSave:
+		return ui.handleEventKeyInputSave(ev) || ui.handleKeyQuit(ev)
+	case ModeInputPipe:
+		return ui.handleEventKeyInputPipe(ev) || ui.handleKeyQuit(ev)
+	case ModeConfirm:
+		return ui.handleEventKeyConfirm(ev)
 	}
+	return false
+}
 
-	for _, h := range handles {
-		if h(ev) {
-			return true
-		}
+// handleKeyAction invokes the action bound to the keys typed in the mode.
+// The digits typed before the keys are the count of the action, such as
+// "10j".
+func (ui *UI) handleKeyAction(ev *tcell.EventKey) bool {
+	if ui.pending != nil {
+		return ui.handleKeyPending(ev)
 	}
 
-	return false
+	mode := ui.mode
+	if mode == ModeFollow {
+		mode = ModeNormal
+	}
+	k := keyStrokeOf(ev)
+	if len(ui.keys) == 0 && k.isDigit() && (k.ch != '0' || ui.count > 0) {
+		ui.count = ui.count*10 + int(k.ch-'0')
+		return true
+	}
+
+	ui.keys = append(ui.keys, k)
+	node := ui.keymap.lookup(mode, ui.keys)
+	if node == nil && len(ui.keys) > 1 {
+		// the key not following the sequence starts a new sequence with the
+		// count, such as "j" of "3gj"
+		ui.keys = nil
+		return ui.handleKeyAction(ev)
+	}
+	if node == nil {
+		ui.keys, ui.count = nil, 0
+		return false
+	}
+	if len(node.action) == 0 {
+		// wait for the following keys
+		return true
+	}
+	count := ui.count
+	ui.keys, ui.count = nil, 0
+	if ui.mode == ModeFollow && !followActions[node.action] {
+		return false
+	}
+	actions[mode][node.action](ui, count)
+	return true
+}
+
+// handleKeyPending handles the key following the prefix key such as "m" of
+// "ma".  The key is consumed even if it is not valid.
+func (ui *UI) handleKeyPending(ev *tcell.EventKey) bool {
+	f := ui.pending
+	ui.pending = nil
+	ui.count = 0
+	if ev.Key() != tcell.KeyRune || ev.Rune() < 'a' || ev.Rune() > 'z' {
+		return true
+	}
+	f(ui, ev.Rune())
+	return true
 }
 
 func (ui *UI) handleKeyQuit(ev *tcell.EventKey) bool {
@@ -43,127 +85,67 @@ func (ui *UI) handleKeyQuit(ev *tcell.EventKey) bool {
 	case tcell.KeyCtrlC:
 		ui.listener.OnQuit()
 		return true
-	case tcell.KeyRune:
-		switch ev.Rune() {
-		case 'q':
-			ui.listener.OnQuit()
-			return true
-		}
 	}
 	return false
 }
 
-func (ui *UI) handleKeyInputFind(ev *tcell.EventKey) bool {
+func (ui *UI) handleEventKeyInput(ev *tcell.EventKey) bool {
 	switch ev.Key() {
-	case tcell.KeyRune:
-		switch ev.Rune() {
-		case '/':
-			ui.enterFindInputMode()
-			return true
-		}
+	case tcell.KeyEnter:
+		ui.startFind()
+		return true
+	case tcell.KeyEscape:
+		ui.startFind()
+		return true
 	}
-	return false
+	return ui.input.HandleEvent(ev)
 }
 
-func (ui *UI) handleKeyToggleFollowMode(ev *tcell.EventKey) bool {
+func (ui *UI) handleEventKeyInputTime(ev *tcell.EventKey) bool {
 	switch ev.Key() {
-	case tcell.KeyRune:
-		switch ev.Rune() {
-		case 'f':
-			ui.toggleFollowMode()
-			return true
-		}
+	case tcell.KeyEnter:
+		ui.startGoToTime()
+		return true
+	case tcell.KeyEscape:
+		ui.cancelInput()
+		return true
 	}
-	return false
+	return ui.input.HandleEvent(ev)
 }
 
-func (ui *UI) handleKeySelectContainer(ev *tcell.EventKey) bool {
+func (ui *UI) handleEventKeyInputSave(ev *tcell.EventKey) bool {
 	switch ev.Key() {
-	case tcell.KeyCtrlP:
-		ui.pods.SelectPrev()
-		ui.pager.SetKeyword("")
-		return true
-	case tcell.KeyCtrlN:
-		ui.pods.SelectNext()
-		ui.pager.SetKeyword("")
+	case tcell.KeyEnter:
+		ui.startSave()
 		return true
-	case tcell.KeyTab:
-		ui.containers.SelectNext()
+	case tcell.KeyEscape:
+		ui.cancelInput()
 		return true
 	}
-	return false
+	return ui.input.HandleEvent(ev)
 }
 
-func (ui *UI) handleKeyScroll(ev *tcell.EventKey) bool {
+func (ui *UI) handleEventKeyInputPipe(ev *tcell.EventKey) bool {
 	switch ev.Key() {
-	case tcell.KeyCtrlD:
-		ui.scrollHalfPageDown()
-		return true
-	case tcell.KeyCtrlU:
-		ui.scrollHalfPageUp()
-		return true
-	case tcell.KeyCtrlB:
-		ui.scrollPageDown()
+	case tcell.KeyEnter:
+		ui.startPipe()
 		return true
-	case tcell.KeyCtrlF:
-		ui.scrollPageUp()
+	case tcell.KeyEscape:
+		ui.cancelInput()
 		return true
-	case tcell.KeyUp:
-		ui.scrollUp()
-	case tcell.KeyDown:
-		ui.scrollDown()
-	case tcell.KeyRight:
-		ui.scrollHaftPageRight()
-	case tcell.KeyLeft:
-		ui.scrollHalfPageLeft()
-	case tcell.KeyRune:
-		switch ev.Rune() {
-		case 'k':
-			ui.scrollUp()
-			return true
-		case 'j':
-			ui.scrollDown()
-			return true
-		case 'g':
-			ui.scrollToTop()
-			return true
-		case 'G':
-			ui.scrollToBottom()
-			return true
-		case 'h':
-			ui.scrollHalfPageLeft()
-			return true
-		case 'l':
-			ui.scrollHaftPageRight()
-			return true
-		}
 	}
-	return false
+	return ui.input.HandleEvent(ev)
 }
 
-func (ui *UI) handleKeyFind(ev *tcell.EventKey) bool {
+func (ui *UI) handleEventKeyConfirm(ev *tcell.EventKey) bool {
 	switch ev.Key() {
 	case tcell.KeyRune:
 		switch ev.Rune() {
-		case 'n':
-			ui.findNext()
-			return true
-		case 'N':
-			ui.findPrev()
+		case 'y', 'Y':
+			ui.answerConfirm(true)
 			return true
 		}
 	}
-	return false
-}
-
-func (ui *UI) handleEventKeyInput(ev *tcell.EventKey) bool {
-	switch ev.Key() {
-	case tcell.KeyEnter:
-		ui.startFind()
-		return true
-	case tcell.KeyEscape:
-		ui.startFind()
-		return true
-	}
-	return ui.input.HandleEvent(ev)
+	ui.answerConfirm(false)
+	return true
 }
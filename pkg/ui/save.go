@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// SaveOptions is options to save lines in the pager to a file
+type SaveOptions struct {
+	// VisibleOnly saves only lines shown by the level filter
+	VisibleOnly bool
+
+	// Timestamps prefixes each line with its timestamp
+	Timestamps bool
+
+	// Prefix prefixes each line with the pod and the container name
+	Prefix bool
+}
+
+// parseSaveInput parses the input of "save to" prompt.  The input is a path
+// optionally preceded by flags: -v for visible lines only, -t for timestamps
+// and -p for pod/container prefixes.  The flags can be combined as "-tp".
+func parseSaveInput(s string) (string, SaveOptions, error) {
+	var opts SaveOptions
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "-") {
+		i := strings.IndexByte(s, ' ')
+		if i == -1 {
+			i = len(s)
+		}
+		for _, c := range s[1:i] {
+			switch c {
+			case 'v':
+				opts.VisibleOnly = true
+			case 't':
+				opts.Timestamps = true
+			case 'p':
+				opts.Prefix = true
+			default:
+				return "", opts, errors.Errorf("unknown flag: -%c", c)
+			}
+		}
+		s = strings.TrimSpace(s[i:])
+	}
+	if len(s) == 0 {
+		return "", opts, errors.New("no file name")
+	}
+	return s, opts, nil
+}
+
+// expandPath replaces the leading "~" in the path with the home directory
+func expandPath(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[1:]), nil
+}
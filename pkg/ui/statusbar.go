@@ -5,22 +5,34 @@ import (
 
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"github.com/ueokande/logbook/pkg/level"
 )
 
 var (
 	styleStatusBarModeNormal = tcell.StyleDefault.Background(tcell.ColorYellowGreen).Foreground(tcell.ColorDarkGreen).Bold(true)
 	styleStatusBarModeFollow = tcell.StyleDefault.Background(tcell.ColorRed).Foreground(tcell.ColorWhite).Bold(true)
+	styleStatusBarModeVisual = tcell.StyleDefault.Background(tcell.ColorPurple).Foreground(tcell.ColorWhite).Bold(true)
 	styleStatusBarContext    = tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorSilver)
 	styleStatusBarPods       = tcell.StyleDefault.Background(tcell.ColorGray).Foreground(tcell.ColorWhite)
 	styleStatusBarScroll     = tcell.StyleDefault.Background(tcell.ColorGray).Foreground(tcell.ColorWhite)
+	styleStatusBarLevels     = tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorSilver)
+	styleStatusBarErrors     = tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorRed).Bold(true)
+	styleStatusBarWarnings   = tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorYellow).Bold(true)
+	styleStatusBarFilter     = tcell.StyleDefault.Background(tcell.ColorNavy).Foreground(tcell.ColorWhite)
+	styleStatusBarWorkload   = tcell.StyleDefault.Background(tcell.ColorTeal).Foreground(tcell.ColorWhite)
+	styleStatusBarMessage    = tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite).Bold(true)
 )
 
 // StatusBar is a status bar on the bottom of the UI
 type StatusBar struct {
-	mode    *views.Text
-	pods    *views.Text
-	context *views.Text
-	scroll  *views.Text
+	mode     *views.Text
+	pods     *views.Text
+	filter   *views.Text
+	workload *views.Text
+	message  *views.Text
+	context  *views.Text
+	levels   *views.Text
+	scroll   *views.Text
 	views.BoxLayout
 }
 
@@ -30,21 +42,37 @@ func NewStatusBar() *StatusBar {
 	mode.SetStyle(styleStatusBarPods)
 	pods := &views.Text{}
 	pods.SetStyle(styleStatusBarPods)
+	filter := &views.Text{}
+	filter.SetStyle(styleStatusBarFilter)
+	workload := &views.Text{}
+	workload.SetStyle(styleStatusBarWorkload)
+	message := &views.Text{}
+	message.SetStyle(styleStatusBarMessage)
 	context := &views.Text{}
 	context.SetAlignment(views.AlignMiddle)
 	context.SetStyle(styleStatusBarContext)
+	levels := &views.Text{}
+	levels.SetStyle(styleStatusBarLevels)
 	scroll := &views.Text{}
 	scroll.SetStyle(styleStatusBarScroll)
 
 	w := &StatusBar{
-		mode:    mode,
-		pods:    pods,
-		context: context,
-		scroll:  scroll,
+		mode:     mode,
+		pods:     pods,
+		filter:   filter,
+		workload: workload,
+		message:  message,
+		context:  context,
+		levels:   levels,
+		scroll:   scroll,
 	}
 	w.AddWidget(mode, 0)
 	w.AddWidget(pods, 0)
+	w.AddWidget(filter, 0)
+	w.AddWidget(workload, 0)
+	w.AddWidget(message, 0)
 	w.AddWidget(context, 1)
+	w.AddWidget(levels, 0)
 	w.AddWidget(scroll, 0)
 	return w
 }
@@ -58,6 +86,9 @@ func (w *StatusBar) SetMode(mode Mode) {
 	case ModeFollow:
 		w.mode.SetText(" FOLLOW ")
 		w.mode.SetStyle(styleStatusBarModeFollow)
+	case ModeVisual:
+		w.mode.SetText(" VISUAL ")
+		w.mode.SetStyle(styleStatusBarModeVisual)
 	default:
 		panic("unsupported mode")
 	}
@@ -78,3 +109,45 @@ func (w *StatusBar) SetPodCount(count int) {
 func (w *StatusBar) SetScroll(percent int) {
 	w.scroll.SetText(fmt.Sprintf(" %d%% ", percent))
 }
+
+// SetLevelCounts sets the count of the error and warning lines
+func (w *StatusBar) SetLevelCounts(errors, warnings int) {
+	e := fmt.Sprintf("E:%d", errors)
+	w.levels.SetText(fmt.Sprintf(" %s W:%d ", e, warnings))
+	for i := 1; i < len(e)+1; i++ {
+		w.levels.SetStyleAt(i, styleStatusBarErrors)
+	}
+	for i := len(e) + 2; i < len(w.levels.Text())-1; i++ {
+		w.levels.SetStyleAt(i, styleStatusBarWarnings)
+	}
+}
+
+// SetMinLevel sets the minimum level of the lines shown in the pager.  The
+// filter is hidden if the level is level.Unknown.
+func (w *StatusBar) SetMinLevel(l level.Level) {
+	if l == level.Unknown {
+		w.filter.SetText("")
+		return
+	}
+	w.filter.SetText(fmt.Sprintf(" >=%s ", l))
+}
+
+// SetWorkload sets the workload followed on the status bar.  The workload is
+// hidden if it is empty.
+func (w *StatusBar) SetWorkload(workload string) {
+	if len(workload) == 0 {
+		w.workload.SetText("")
+		return
+	}
+	w.workload.SetText(" " + workload + " ")
+}
+
+// SetMessage sets the message on the status bar.  The message is hidden if it
+// is empty.
+func (w *StatusBar) SetMessage(msg string) {
+	if len(msg) == 0 {
+		w.message.SetText("")
+		return
+	}
+	w.message.SetText(" " + msg + " ")
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell"
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// styles returns the styles of the UI and the widgets by their names
+func styles() map[string]*tcell.Style {
+	m := widgets.Styles()
+	for name, style := range map[string]*tcell.Style{
+		"pod.running":           &stylePodActive,
+		"pod.error":             &stylePodError,
+		"pod.pending":           &stylePodPending,
+		"pod.deleted":           &stylePodDeleted,
+		"pod.virtual":           &stylePodVirtual,
+		"statusbar.mode.normal": &styleStatusBarModeNormal,
+		"statusbar.mode.follow": &styleStatusBarModeFollow,
+		"statusbar.mode.visual": &styleStatusBarModeVisual,
+		"statusbar.context":     &styleStatusBarContext,
+		"statusbar.pods":        &styleStatusBarPods,
+		"statusbar.scroll":      &styleStatusBarScroll,
+		"statusbar.levels":      &styleStatusBarLevels,
+		"statusbar.errors":      &styleStatusBarErrors,
+		"statusbar.warnings":    &styleStatusBarWarnings,
+		"statusbar.filter":      &styleStatusBarFilter,
+		"statusbar.workload":    &styleStatusBarWorkload,
+		"statusbar.message":     &styleStatusBarMessage,
+		"json.time":             &styleJSONTime,
+		"json.message":          &styleJSONMessage,
+		"json.key":              &styleJSONKey,
+		"level.error":           &styleLevelError,
+		"level.warn":            &styleLevelWarn,
+		"level.info":            &styleLevelInfo,
+		"level.debug":           &styleLevelDebug,
+	} {
+		m[name] = style
+	}
+	return m
+}
+
+// SetColors changes the styles of the elements by their names, such as
+// {"pod.running": "green", "statusbar.message": "white on navy bold"}.  No
+// styles are changed if any of them is invalid.  It must be called before
+// the UI is created.
+func SetColors(colors map[string]string) error {
+	names := make([]string, 0, len(colors))
+	for name := range colors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	all := styles()
+	parsed := make(map[string]tcell.Style)
+	for _, name := range names {
+		if _, ok := all[name]; !ok {
+			return errors.Errorf("unknown element %q", name)
+		}
+		style, err := parseStyle(colors[name])
+		if err != nil {
+			return errors.Wrapf(err, "element %s", name)
+		}
+		parsed[name] = style
+	}
+	for name, style := range parsed {
+		*all[name] = style
+	}
+	return nil
+}
+
+// parseStyle parses the style of the form "<fg> [on <bg>] [attributes...]",
+// such as "white on #005f87 bold".  The colors are names of the colors or
+// "#rrggbb", and the attributes are bold, dim, underline, reverse or blink.
+func parseStyle(value string) (tcell.Style, error) {
+	style := tcell.StyleDefault
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return style, errors.New("empty style")
+	}
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i]; f {
+		case "bold":
+			style = style.Bold(true)
+		case "dim":
+			style = style.Dim(true)
+		case "underline":
+			style = style.Underline(true)
+		case "reverse":
+			style = style.Reverse(true)
+		case "blink":
+			style = style.Blink(true)
+		case "on":
+			if i+1 >= len(fields) {
+				return style, errors.Errorf("no background color in %q", value)
+			}
+			i++
+			c, err := parseColor(fields[i])
+			if err != nil {
+				return style, err
+			}
+			style = style.Background(c)
+		default:
+			if i > 0 {
+				return style, errors.Errorf("unexpected %q in %q", f, value)
+			}
+			c, err := parseColor(f)
+			if err != nil {
+				return style, err
+			}
+			style = style.Foreground(c)
+		}
+	}
+	return style, nil
+}
+
+func parseColor(name string) (tcell.Color, error) {
+	if name == "default" {
+		return tcell.ColorDefault, nil
+	}
+	if c := tcell.GetColor(name); c != tcell.ColorDefault {
+		return c, nil
+	}
+	return tcell.ColorDefault, errors.Errorf("unknown color %q", name)
+}
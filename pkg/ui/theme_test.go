@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func TestParseStyle(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected tcell.Style
+	}{
+		{"red", tcell.StyleDefault.Foreground(tcell.ColorRed)},
+		{"White on #005f87 bold", tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.NewHexColor(0x005f87)).Bold(true)},
+		{"default on navy", tcell.StyleDefault.Background(tcell.ColorNavy)},
+		{"on gray underline reverse", tcell.StyleDefault.Background(tcell.ColorGray).Underline(true).Reverse(true)},
+	}
+	for _, c := range cases {
+		style, err := parseStyle(c.value)
+		if err != nil {
+			t.Errorf("parseStyle(%q): %v", c.value, err)
+		} else if style != c.expected {
+			t.Errorf("parseStyle(%q) = %v, want %v", c.value, style, c.expected)
+		}
+	}
+
+	for _, value := range []string{"", "redd", "red on", "red green"} {
+		if _, err := parseStyle(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestSetColors(t *testing.T) {
+	defer func(s tcell.Style) { stylePodActive = s }(stylePodActive)
+
+	err := SetColors(map[string]string{"pod.running": "blue", "pod.unknown": "red"})
+	if err == nil || !strings.Contains(err.Error(), `unknown element "pod.unknown"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if stylePodActive == tcell.StyleDefault.Foreground(tcell.ColorBlue) {
+		t.Error("the style is changed by the invalid colors")
+	}
+
+	if err := SetColors(map[string]string{"pod.running": "blue"}); err != nil {
+		t.Fatal(err)
+	}
+	h := newHarness(t, 60, 10)
+	h.ui.AddPod("nginx", types.PodRunning)
+	h.expectRow(0, "nginx")
+	if fg, _, _ := h.style(0, 0).Decompose(); fg != tcell.ColorBlue {
+		t.Errorf("unexpected color: %v", fg)
+	}
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// parseTime parses a time in the input of "go to time".  It accepts a
+// relative time such as "-5m", RFC3339, and a time of day such as "14:03:22"
+// in local time zone.  The time of day is treated as yesterday if the time
+// is in the future.
+func parseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "-") {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return time.Time{}, errors.Errorf("invalid duration: %s", s)
+		}
+		return now.Add(d), nil
+	}
+
+	for _, layout := range []string{time.RFC3339Nano, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		t, err := time.ParseInLocation(layout, s, now.Location())
+		if err != nil {
+			continue
+		}
+		y, m, d := now.Date()
+		t = time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+		if t.After(now) {
+			t = t.AddDate(0, 0, -1)
+		}
+		return t, nil
+	}
+	return time.Time{}, errors.Errorf("invalid time: %s", s)
+}
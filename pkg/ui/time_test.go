@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("JST", 9*60*60)
+	now := time.Date(2019, 7, 1, 12, 0, 0, 0, loc)
+
+	cases := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"-5m", now.Add(-5 * time.Minute)},
+		{"-1h30m", now.Add(-90 * time.Minute)},
+		{"2019-07-01T01:02:03Z", time.Date(2019, 7, 1, 1, 2, 3, 0, time.UTC)},
+		{"2019-07-01T01:02:03.5+09:00", time.Date(2019, 7, 1, 1, 2, 3, 500000000, loc)},
+		{"2019-06-30 23:00:00", time.Date(2019, 6, 30, 23, 0, 0, 0, loc)},
+		{"11:03:22", time.Date(2019, 7, 1, 11, 3, 22, 0, loc)},
+		{"14:03", time.Date(2019, 6, 30, 14, 3, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		actual, err := parseTime(c.input, now)
+		if err != nil {
+			t.Errorf("parseTime(%q): %v", c.input, err)
+			continue
+		}
+		if !actual.Equal(c.expected) {
+			t.Errorf("parseTime(%q) = %v, want %v", c.input, actual, c.expected)
+		}
+	}
+
+	for _, input := range []string{"", "-5x", "yesterday", "25:00"} {
+		if _, err := parseTime(input, now); err == nil {
+			t.Errorf("parseTime(%q) should fail", input)
+		}
+	}
+}
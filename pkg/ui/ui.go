@@ -1,8 +1,17 @@
 package ui
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"github.com/ueokande/logbook/pkg/ansi"
+	"github.com/ueokande/logbook/pkg/jsonlog"
+	"github.com/ueokande/logbook/pkg/level"
 	"github.com/ueokande/logbook/pkg/types"
 	"github.com/ueokande/logbook/pkg/widgets"
 )
@@ -15,12 +24,20 @@ const (
 	ModeNormal    Mode = iota // Normal mode
 	ModeFollow                // Follow mode
 	ModeInputFind             // Input find mode
+	ModePopup                 // Popup mode
+	ModeInputTime             // Input time mode to go to the time
+	ModeInputSave             // Input path mode to save the pager
+	ModeConfirm               // Confirm mode to answer yes or no
+	ModeInputPipe             // Input command mode to pipe the pager
+	ModeVisual                // Visual mode to select lines
 )
 
 var (
 	stylePodActive  = tcell.StyleDefault.Foreground(tcell.ColorGreen)
 	stylePodError   = tcell.StyleDefault.Foreground(tcell.ColorRed)
 	stylePodPending = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	stylePodDeleted = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	stylePodVirtual = tcell.StyleDefault.Foreground(tcell.ColorTeal)
 )
 
 // EventListener is a listener interface for UI events
@@ -33,14 +50,62 @@ type EventListener interface {
 
 	// OnContainerSelected is invoked when the selected container is changed
 	OnContainerSelected(name string, index int)
+
+	// OnLogsSinceRequested is invoked when the logs older than the lines in
+	// the pager are required
+	OnLogsSinceRequested(since time.Time)
+
+	// OnSaveRequested is invoked when the lines in the pager are required to
+	// be saved to the file at path
+	OnSaveRequested(path string, lines []widgets.Line, opts SaveOptions)
+
+	// OnPipeRequested is invoked when the lines in the pager are required to
+	// be piped to the shell command
+	OnPipeRequested(command string, lines []widgets.Line)
+
+	// OnPagerRequested is invoked when the lines in the pager are required to
+	// be opened in $PAGER
+	OnPagerRequested(lines []widgets.Line)
+
+	// OnEditorRequested is invoked when the lines in the pager are required
+	// to be opened in $EDITOR
+	OnEditorRequested(lines []widgets.Line)
+
+	// OnCopyRequested is invoked when the lines are required to be copied
+	// to the clipboard
+	OnCopyRequested(lines []widgets.Line)
+
+	// OnFollowWorkloadRequested is invoked when following the workload of
+	// the selected pod is required to be enabled or disabled
+	OnFollowWorkloadRequested()
+
+	// OnSwitchListRequested is invoked when the list is required to be
+	// switched, such as between pods and workloads
+	OnSwitchListRequested()
 }
 
 type nopListener struct{}
 
+func (l nopListener) OnCopyRequested(lines []widgets.Line) {}
+
+func (l nopListener) OnEditorRequested(lines []widgets.Line) {}
+
+func (l nopListener) OnPagerRequested(lines []widgets.Line) {}
+
+func (l nopListener) OnPipeRequested(command string, lines []widgets.Line) {}
+
+func (l nopListener) OnSaveRequested(path string, lines []widgets.Line, opts SaveOptions) {}
+
+func (l nopListener) OnLogsSinceRequested(since time.Time) {}
+
 func (l nopListener) OnContainerSelected(name string, index int) {}
 
 func (l nopListener) OnPodSelected(name string, index int) {}
 
+func (l nopListener) OnFollowWorkloadRequested() {}
+
+func (l nopListener) OnSwitchListRequested() {}
+
 func (l nopListener) OnQuit() {}
 
 // UI is an user interface for the logbook
@@ -49,11 +114,43 @@ type UI struct {
 	pods       *widgets.ListView
 	containers *widgets.Tabs
 	pager      *widgets.Pager
+	popup      *widgets.Popup
 	statusbar  *StatusBar
 
-	mode     Mode
-	keyword  string
-	listener EventListener
+	mode       Mode
+	popupMode  Mode
+	keyword    string
+	jsonMode   bool
+	jsonFields jsonlog.Fields
+	errors     int
+	warnings   int
+	confirm    func()
+	pipeLines  []widgets.Line
+	pending    func(ui *UI, name rune)
+	popupItems []int
+	popupIndex int
+	listener   EventListener
+
+	// keymap is the actions bound to the keys, and keys and count are the
+	// keys and the count typed before the action
+	keymap *Keymap
+	keys   []keyStroke
+	count  int
+
+	// followByDefault enables follow mode when the pager is cleared, such
+	// as on selecting a container
+	followByDefault bool
+
+	// deletedPods is a set of the pods marked as deleted, which are still
+	// shown in the list
+	deletedPods map[string]bool
+
+	// virtualPods is a set of the items which are not pods, such as events
+	// in the namespace
+	virtualPods map[string]bool
+
+	// podParents is the parents of the pods nested beneath them
+	podParents map[string]string
 
 	views.BoxLayout
 }
@@ -83,14 +180,23 @@ func NewUI() *UI {
 		pods:       pods,
 		containers: containers,
 		pager:      pager,
+		popup:      widgets.NewPopup(),
 		statusbar:  statusbar,
+		jsonFields: jsonlog.DefaultFields,
 		listener:   &nopListener{},
+		keymap:     DefaultKeymap(),
+
+		deletedPods: make(map[string]bool),
+		virtualPods: make(map[string]bool),
+		podParents:  make(map[string]string),
 	}
 
 	ui.SetOrientation(views.Vertical)
 	ui.AddWidget(mainLayout, 1)
 	ui.AddWidget(statusbar, 0)
 
+	statusbar.SetLevelCounts(0, 0)
+
 	pods.Watch(ui)
 	containers.Watch(ui)
 
@@ -102,23 +208,178 @@ func (ui *UI) WatchUIEvents(l EventListener) {
 	ui.listener = l
 }
 
+// Draw draws the UI, and the popup over the UI if it is opened
+func (ui *UI) Draw() {
+	ui.BoxLayout.Draw()
+	if ui.mode == ModePopup {
+		ui.popup.Draw()
+	}
+}
+
+// Resize is called when our View changes sizes.
+func (ui *UI) Resize() {
+	ui.BoxLayout.Resize()
+	ui.popup.Resize()
+}
+
+// SetView sets the View object used for the UI
+func (ui *UI) SetView(view views.View) {
+	ui.BoxLayout.SetView(view)
+	ui.popup.SetView(view)
+}
+
+// SetKeymap sets the actions bound to the keys.  The default keys are used
+// if the keymap is nil.
+func (ui *UI) SetKeymap(keymap *Keymap) {
+	if keymap == nil {
+		keymap = DefaultKeymap()
+	}
+	ui.keymap = keymap
+}
+
+// SetMaxLines sets the max count of the lines kept in the pager.  The lines
+// are never dropped if the max is 0.
+func (ui *UI) SetMaxLines(max int) {
+	ui.pager.SetMaxLines(max)
+}
+
+// SetJSONFields sets the field names of the time, the level and the message
+// in JSON logs
+func (ui *UI) SetJSONFields(fields jsonlog.Fields) {
+	ui.jsonFields = fields
+	if ui.jsonMode {
+		ui.pager.SetFormatter(jsonFormatter(ui.jsonFields))
+	}
+}
+
+// SetJSONMode enables or disables rendering JSON logs as "time level msg
+// key=value ..."
+func (ui *UI) SetJSONMode(enabled bool) {
+	ui.jsonMode = enabled
+	if enabled {
+		ui.pager.SetFormatter(jsonFormatter(ui.jsonFields))
+	} else {
+		ui.pager.SetFormatter(nil)
+	}
+	ui.updateScrollStatus()
+}
+
 // AddPod adds a pod by the name and its status to the list view.
 func (ui *UI) AddPod(name string, status types.PodStatus) {
-	ui.pods.AddItem(name, podStatusStyle(status))
-	ui.statusbar.SetPodCount(ui.pods.ItemCount())
+	ui.AddNestedPod(name, "", status)
+}
+
+// AddNestedPod adds a pod by the name and its status beneath the parent to
+// the list view, such as a pod of the workload.  The pod is added on the top
+// level if the parent is empty.
+func (ui *UI) AddNestedPod(name, parent string, status types.PodStatus) {
+	ui.pods.InsertItem(ui.podIndex(parent), name, podStatusStyle(status))
+	if len(parent) > 0 {
+		ui.podParents[name] = parent
+		ui.pods.SetLabel(name, ui.podLabel(name))
+	}
+	ui.updatePodCount()
+}
+
+// MovePod moves the pod by the name beneath the parent.  The pod is moved to
+// the top level if the parent is empty.
+func (ui *UI) MovePod(name, parent string) {
+	delete(ui.podParents, name)
+	index := ui.podIndex(parent)
+	if i := ui.pods.ItemIndex(name); i < index {
+		// the pod is removed before the index
+		index--
+	}
+	ui.pods.MoveItem(name, index)
+	if len(parent) > 0 {
+		ui.podParents[name] = parent
+	}
+	ui.pods.SetLabel(name, ui.podLabel(name))
 }
 
 // DeletePod deletes pod by the name on the list view.
 func (ui *UI) DeletePod(name string) {
 	ui.pods.DeleteItem(name)
-	ui.statusbar.SetPodCount(ui.pods.ItemCount())
+	delete(ui.deletedPods, name)
+	delete(ui.virtualPods, name)
+	delete(ui.podParents, name)
+	ui.updatePodCount()
+}
+
+// ClearPods clears pods in the list view
+func (ui *UI) ClearPods() {
+	ui.pods.Clear()
+	ui.deletedPods = make(map[string]bool)
+	ui.virtualPods = make(map[string]bool)
+	ui.podParents = make(map[string]string)
+	ui.updatePodCount()
+}
+
+// MarkPodDeleted marks the pod by the name as deleted on the list view
+// without removing it.  The marked pod is not counted in the status bar.  The
+// mark is cleared by SetPodStatus.
+func (ui *UI) MarkPodDeleted(name string) {
+	ui.deletedPods[name] = true
+	ui.pods.SetLabel(name, ui.podLabel(name))
+	ui.pods.SetStyle(name, stylePodDeleted)
+	ui.updatePodCount()
+}
+
+// MarkPodVirtual marks the item by the name as a virtual item which is not a
+// pod, such as events in the namespace.  The marked item is not counted in
+// the status bar.
+func (ui *UI) MarkPodVirtual(name string) {
+	ui.virtualPods[name] = true
+	ui.pods.SetStyle(name, stylePodVirtual)
+	ui.updatePodCount()
 }
 
 // SetPodStatus updates the pod status by name to the status
 func (ui *UI) SetPodStatus(name string, status types.PodStatus) {
+	if ui.virtualPods[name] {
+		return
+	}
+	if ui.deletedPods[name] {
+		delete(ui.deletedPods, name)
+		ui.pods.SetLabel(name, ui.podLabel(name))
+		ui.updatePodCount()
+	}
 	ui.pods.SetStyle(name, podStatusStyle(status))
 }
 
+// podIndex returns the index in the list view to add a pod beneath the
+// parent.  It is next to the pods already nested beneath the parent, or the
+// end of the list if the parent is empty or not found.
+func (ui *UI) podIndex(parent string) int {
+	index := ui.pods.ItemIndex(parent)
+	if len(parent) == 0 || index < 0 {
+		return ui.pods.ItemCount()
+	}
+	index++
+	for _, p := range ui.podParents {
+		if p == parent {
+			index++
+		}
+	}
+	return index
+}
+
+// podLabel returns the label of the pod in the list view
+func (ui *UI) podLabel(name string) string {
+	label := name
+	if len(ui.podParents[name]) > 0 {
+		label = "  " + label
+	}
+	if ui.deletedPods[name] {
+		label += " (deleted)"
+	}
+	return label
+}
+
+func (ui *UI) updatePodCount() {
+	ui.statusbar.SetPodCount(ui.pods.ItemCount() - len(ui.deletedPods) - len(ui.virtualPods))
+}
+
 // AddContainer adds container by the name into the tabs
 func (ui *UI) AddContainer(name string) {
 	ui.containers.AddTab(name)
@@ -129,6 +390,12 @@ func (ui *UI) ClearContainers() {
 	ui.containers.Clear()
 }
 
+// SetWorkload sets the workload followed on the status bar.  The workload is
+// hidden if it is empty.
+func (ui *UI) SetWorkload(workload string) {
+	ui.statusbar.SetWorkload(workload)
+}
+
 // SetContext sets kubenetes context (the cluster name and the namespace)
 func (ui *UI) SetContext(cluster, namespace string) {
 	ui.statusbar.SetContext(cluster, namespace)
@@ -152,9 +419,18 @@ func (ui *UI) HandleEvent(ev tcell.Event) bool {
 	return false
 }
 
-// AddPagerText adds text line into the pager
-func (ui *UI) AddPagerText(line string) {
-	ui.pager.AppendLine(line)
+// AddPagerText adds text line and its timestamp into the pager.  The
+// timestamp can be zero if it is unknown.
+func (ui *UI) AddPagerText(line string, timestamp time.Time) {
+	switch ui.pager.AppendTimedLine(line, timestamp) {
+	case level.Error:
+		ui.errors++
+		ui.statusbar.SetLevelCounts(ui.errors, ui.warnings)
+	case level.Warn:
+		ui.warnings++
+		ui.statusbar.SetLevelCounts(ui.errors, ui.warnings)
+	}
+
 	if ui.mode == ModeFollow {
 		ui.pager.ScrollToBottom()
 	}
@@ -164,8 +440,14 @@ func (ui *UI) AddPagerText(line string) {
 // ClearPager clears the pager
 func (ui *UI) ClearPager() {
 	ui.pager.ClearText()
+	ui.errors, ui.warnings = 0, 0
+	ui.statusbar.SetLevelCounts(ui.errors, ui.warnings)
 	ui.updateScrollStatus()
-	ui.DisableFollowMode()
+	if ui.followByDefault {
+		ui.EnableFollowMode()
+	} else {
+		ui.DisableFollowMode()
+	}
 }
 
 // SetStatusMode sets the mode in the status bar
@@ -229,6 +511,37 @@ func (ui *UI) scrollToBottom() {
 	ui.updateScrollStatus()
 }
 
+// scrollToTopOrLine scrolls to the line numbered count, or to the top if the
+// count is not typed
+func (ui *UI) scrollToTopOrLine(count int) {
+	if count == 0 {
+		ui.scrollToTop()
+		return
+	}
+	ui.scrollToLine(count)
+}
+
+// scrollToBottomOrLine scrolls to the line numbered count, or to the bottom
+// if the count is not typed
+func (ui *UI) scrollToBottomOrLine(count int) {
+	if count == 0 {
+		ui.scrollToBottom()
+		return
+	}
+	ui.scrollToLine(count)
+}
+
+func (ui *UI) scrollToLine(number int) {
+	if ui.mode == ModeFollow {
+		return
+	}
+	if !ui.pager.ScrollToLine(number) {
+		ui.ShowMessage(fmt.Sprintf("Line %d not found", number))
+		return
+	}
+	ui.updateScrollStatus()
+}
+
 func (ui *UI) scrollHalfPageUp() {
 	if ui.mode == ModeFollow {
 		return
@@ -253,6 +566,88 @@ func (ui *UI) scrollHaftPageRight() {
 	ui.updateScrollStatus()
 }
 
+// cycleMinLevel changes the minimum level of the lines shown in the pager in
+// order of all -> debug -> info -> warn -> error -> all
+func (ui *UI) cycleMinLevel() {
+	switch ui.pager.MinLevel() {
+	case level.Unknown:
+		ui.SetMinLevel(level.Debug)
+	case level.Debug:
+		ui.SetMinLevel(level.Info)
+	case level.Info:
+		ui.SetMinLevel(level.Warn)
+	case level.Warn:
+		ui.SetMinLevel(level.Error)
+	default:
+		ui.SetMinLevel(level.Unknown)
+	}
+}
+
+// SetMinLevel sets the minimum level of the lines shown in the pager.  All
+// lines are shown if the level is Unknown.
+func (ui *UI) SetMinLevel(l level.Level) {
+	ui.pager.SetMinLevel(l)
+	ui.statusbar.SetMinLevel(l)
+	if ui.mode == ModeFollow {
+		ui.pager.ScrollToBottom()
+	}
+	ui.updateScrollStatus()
+}
+
+func (ui *UI) toggleWrap() {
+	ui.SetWrap(!ui.pager.Wrap())
+}
+
+// SetWrap enables or disables wrapping long lines in the pager
+func (ui *UI) SetWrap(wrap bool) {
+	ui.pager.SetWrap(wrap)
+	if ui.mode == ModeFollow {
+		ui.pager.ScrollToBottom()
+	}
+	ui.updateScrollStatus()
+}
+
+func (ui *UI) toggleGutter() {
+	ui.SetGutter(!ui.pager.Gutter())
+}
+
+// SetGutter shows or hides line numbers and timestamps in the pager
+func (ui *UI) SetGutter(show bool) {
+	ui.pager.SetGutter(show)
+	if ui.mode == ModeFollow {
+		ui.pager.ScrollToBottom()
+	}
+}
+
+// cycleTimestampFormat changes the format of the timestamps in the gutter in
+// order of local -> UTC -> relative
+func (ui *UI) cycleTimestampFormat() {
+	switch ui.pager.TimestampFormat() {
+	case widgets.TimestampLocal:
+		ui.SetTimestampFormat(widgets.TimestampUTC)
+	case widgets.TimestampUTC:
+		ui.SetTimestampFormat(widgets.TimestampRelative)
+	default:
+		ui.SetTimestampFormat(widgets.TimestampLocal)
+	}
+}
+
+// SetTimestampFormat sets the format of the timestamps in the gutter
+func (ui *UI) SetTimestampFormat(format widgets.TimestampFormat) {
+	ui.pager.SetTimestampFormat(format)
+}
+
+// SetFollowByDefault enables or disables follow mode now and whenever the
+// pager is cleared, such as on selecting a container
+func (ui *UI) SetFollowByDefault(enabled bool) {
+	ui.followByDefault = enabled
+	if enabled {
+		ui.EnableFollowMode()
+	} else {
+		ui.DisableFollowMode()
+	}
+}
+
 func (ui *UI) toggleFollowMode() {
 	if ui.mode == ModeFollow {
 		ui.DisableFollowMode()
@@ -280,6 +675,11 @@ func (ui *UI) SelectPodAt(index int) {
 	ui.pods.SelectAt(index)
 }
 
+// SelectPod selects a pod by the name
+func (ui *UI) SelectPod(name string) {
+	ui.pods.Select(name)
+}
+
 // SelectContainerAt selects a container by the index
 func (ui *UI) SelectContainerAt(index int) {
 	ui.containers.SelectAt(index)
@@ -291,10 +691,73 @@ func (ui *UI) updateScrollStatus() {
 }
 
 func (ui *UI) enterFindInputMode() {
-	ui.input.SetPrompt("/")
+	ui.enterInputMode(ModeInputFind, "/")
+}
+
+func (ui *UI) enterTimeInputMode() {
+	ui.enterInputMode(ModeInputTime, "Go to time: ")
+}
+
+func (ui *UI) enterSaveInputMode() {
+	ui.enterInputMode(ModeInputSave, "Save to: ")
+}
+
+// enterPipeInputMode enters the mode to input the command to pipe the lines.
+// The selected lines are piped if the lines are being selected.
+func (ui *UI) enterPipeInputMode() {
+	ui.pipeLines = ui.targetLines()
+	if ui.mode == ModeVisual {
+		ui.exitVisualMode()
+	}
+	ui.enterInputMode(ModeInputPipe, "|")
+}
+
+// targetLines returns the selected lines in visual mode, otherwise all lines
+// shown in the pager
+func (ui *UI) targetLines() []widgets.Line {
+	if ui.mode == ModeVisual {
+		return ui.pager.SelectedLines()
+	}
+	return ui.pager.Lines(true)
+}
+
+func (ui *UI) enterVisualMode() {
+	if !ui.pager.StartSelection() {
+		return
+	}
+	ui.mode = ModeVisual
+	ui.statusbar.SetMode(ModeVisual)
+}
+
+func (ui *UI) exitVisualMode() {
+	ui.pager.ClearSelection()
+	ui.mode = ModeNormal
+	ui.statusbar.SetMode(ModeNormal)
+}
+
+func (ui *UI) moveSelection(delta int) {
+	ui.pager.MoveSelection(delta)
+	ui.updateScrollStatus()
+}
+
+func (ui *UI) copySelection() {
+	lines := ui.pager.SelectedLines()
+	ui.exitVisualMode()
+	ui.listener.OnCopyRequested(lines)
+}
+
+// enterConfirmMode asks yes or no with the prompt, and invokes f if yes.
+func (ui *UI) enterConfirmMode(prompt string, f func()) {
+	ui.confirm = f
+	ui.enterInputMode(ModeConfirm, prompt+" (y/n) ")
+}
+
+func (ui *UI) enterInputMode(mode Mode, prompt string) {
+	ui.input.SetPrompt(prompt)
 	ui.input.SetValue("")
-	ui.mode = ModeInputFind
+	ui.mode = mode
 	ui.RemoveWidget(ui.statusbar)
+	ui.RemoveWidget(ui.input)
 	ui.AddWidget(ui.input, 0)
 }
 
@@ -314,10 +777,13 @@ func (ui *UI) findPrev() {
 	ui.pager.FindPrev()
 }
 
+// cancelInput closes the input line and shows the status bar.  It can be
+// called even if the input line is not shown.
 func (ui *UI) cancelInput() {
 	ui.mode = ModeNormal
+	ui.RemoveWidget(ui.input)
 	ui.RemoveWidget(ui.statusbar)
-	ui.AddWidget(ui.input, 0)
+	ui.AddWidget(ui.statusbar, 0)
 }
 
 func (ui *UI) startFind() {
@@ -326,13 +792,243 @@ func (ui *UI) startFind() {
 		// Use previous keyword if the input is empty
 		ui.keyword = keyword
 	}
-	ui.mode = ModeNormal
-	ui.AddWidget(ui.statusbar, 0)
-	ui.RemoveWidget(ui.input)
+	ui.cancelInput()
 	ui.pager.SetKeyword(ui.keyword)
 	ui.pager.FindNext()
 }
 
+// startGoToTime scrolls to the first line at or after the time in the input.
+// It requests older logs to the listener if the time is older than the lines
+// in the pager.
+func (ui *UI) startGoToTime() {
+	value := ui.input.Value()
+	ui.cancelInput()
+	if len(value) == 0 {
+		return
+	}
+
+	t, err := parseTime(value, time.Now())
+	if err != nil {
+		ui.ShowMessage(err.Error())
+		return
+	}
+	first := ui.pager.FirstTime()
+	if first.IsZero() || t.Before(first) {
+		ui.listener.OnLogsSinceRequested(t)
+		return
+	}
+	if !ui.pager.ScrollToTime(t) {
+		ui.ShowMessage("No lines after " + t.Local().Format(time.RFC3339))
+	}
+	ui.updateScrollStatus()
+}
+
+// startSave requests to save the lines in the pager to the path in the
+// input.  It asks to overwrite the file if the file already exists.
+func (ui *UI) startSave() {
+	value := ui.input.Value()
+	ui.cancelInput()
+	if len(strings.TrimSpace(value)) == 0 {
+		return
+	}
+
+	path, opts, err := parseSaveInput(value)
+	if err != nil {
+		ui.ShowMessage(err.Error())
+		return
+	}
+	path, err = expandPath(path)
+	if err != nil {
+		ui.ShowMessage(err.Error())
+		return
+	}
+
+	save := func() {
+		lines := ui.pager.Lines(opts.VisibleOnly)
+		ui.listener.OnSaveRequested(path, lines, opts)
+	}
+	if _, err := os.Stat(path); err == nil {
+		ui.enterConfirmMode(fmt.Sprintf("Overwrite %s?", path), save)
+		return
+	}
+	save()
+}
+
+// startPipe requests to pipe the lines to the command in the input
+func (ui *UI) startPipe() {
+	command := strings.TrimSpace(ui.input.Value())
+	lines := ui.pipeLines
+	ui.pipeLines = nil
+	ui.cancelInput()
+	if len(command) == 0 {
+		return
+	}
+	ui.listener.OnPipeRequested(command, lines)
+}
+
+func (ui *UI) answerConfirm(yes bool) {
+	f := ui.confirm
+	ui.confirm = nil
+	ui.cancelInput()
+	if yes && f != nil {
+		f()
+	}
+}
+
+// ShowMessage shows the message on the status bar.  The message is cleared
+// on the next key input.
+func (ui *UI) ShowMessage(msg string) {
+	ui.statusbar.SetMessage(msg)
+}
+
+func (ui *UI) openJSONPopup() {
+	index := ui.pager.CurrentLine()
+	if index < 0 {
+		return
+	}
+	text, err := jsonlog.Indent(ui.pager.Line(index))
+	if err != nil {
+		return
+	}
+
+	ui.popup.SetTitle(fmt.Sprintf("Line %d", ui.pager.LineNumber(index)))
+	ui.popup.SetLines(strings.Split(text, "\n"))
+	ui.popupMode = ui.mode
+	ui.mode = ModePopup
+}
+
+func (ui *UI) closePopup() {
+	ui.popupItems = nil
+	ui.popup.Pager().ClearSelection()
+	ui.mode = ui.popupMode
+}
+
+func (ui *UI) setMark(name rune) {
+	if ui.pager.SetMark(name) {
+		ui.ShowMessage(fmt.Sprintf("Mark '%c' set", name))
+	}
+}
+
+func (ui *UI) jumpToMark(name rune) {
+	if !ui.pager.JumpToMark(name) {
+		ui.ShowMessage(fmt.Sprintf("Mark '%c' not set", name))
+		return
+	}
+	ui.updateScrollStatus()
+}
+
+func (ui *UI) toggleBookmark() {
+	if ui.pager.ToggleBookmark() {
+		ui.ShowMessage("Bookmark added")
+	} else {
+		ui.ShowMessage("Bookmark removed")
+	}
+}
+
+func (ui *UI) nextBookmark() {
+	if !ui.pager.NextBookmark() {
+		ui.ShowMessage("No more bookmarks")
+		return
+	}
+	ui.updateScrollStatus()
+}
+
+func (ui *UI) prevBookmark() {
+	if !ui.pager.PrevBookmark() {
+		ui.ShowMessage("No more bookmarks")
+		return
+	}
+	ui.updateScrollStatus()
+}
+
+// openBookmarksPopup shows the marked lines in the popup to jump to the line.
+func (ui *UI) openBookmarksPopup() {
+	bookmarks := ui.pager.Bookmarks()
+	if len(bookmarks) == 0 {
+		ui.ShowMessage("No bookmarks")
+		return
+	}
+
+	digits := len(strconv.Itoa(bookmarks[len(bookmarks)-1].Number))
+	lines := make([]string, len(bookmarks))
+	items := make([]int, len(bookmarks))
+	for i, b := range bookmarks {
+		name := '*'
+		if b.Name != 0 {
+			name = b.Name
+		}
+		lines[i] = fmt.Sprintf("%c %*d  %s", name, digits, b.Number, ansi.Strip(b.Text))
+		items[i] = b.Number
+	}
+
+	ui.popup.SetTitle("Bookmarks")
+	ui.popup.SetLines(lines)
+	ui.popupItems = items
+	ui.popupIndex = 0
+	ui.popup.Pager().SelectLine(0)
+	ui.popupMode = ui.mode
+	ui.mode = ModePopup
+}
+
+func (ui *UI) movePopupItem(delta int) {
+	index := ui.popupIndex + delta
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(ui.popupItems) {
+		index = len(ui.popupItems) - 1
+	}
+	ui.popupIndex = index
+	ui.popup.Pager().SelectLine(index)
+}
+
+// scrollPopup moves the selected item in the popup by delta, or scrolls the
+// popup if it is not a list
+func (ui *UI) scrollPopup(delta int) {
+	if ui.popupItems != nil {
+		ui.movePopupItem(delta)
+		return
+	}
+	pager := ui.popup.Pager()
+	for ; delta > 0; delta-- {
+		pager.ScrollDown()
+	}
+	for ; delta < 0; delta++ {
+		pager.ScrollUp()
+	}
+}
+
+// scrollPopupToEdge selects the first or the last item in the popup, or
+// scrolls the popup to the top or the bottom if it is not a list
+func (ui *UI) scrollPopupToEdge(bottom bool) {
+	switch {
+	case ui.popupItems != nil && bottom:
+		ui.movePopupItem(len(ui.popupItems))
+	case ui.popupItems != nil:
+		ui.movePopupItem(-len(ui.popupItems))
+	case bottom:
+		ui.popup.Pager().ScrollToBottom()
+	default:
+		ui.popup.Pager().ScrollToTop()
+	}
+}
+
+// selectPopupItem jumps to the line of the item selected in the popup, or
+// closes the popup if it is not a list
+func (ui *UI) selectPopupItem() {
+	if ui.popupItems == nil {
+		ui.closePopup()
+		return
+	}
+	number := ui.popupItems[ui.popupIndex]
+	ui.closePopup()
+	if ui.mode == ModeFollow {
+		ui.DisableFollowMode()
+	}
+	ui.pager.ScrollToLine(number)
+	ui.updateScrollStatus()
+}
+
 func podStatusStyle(status types.PodStatus) tcell.Style {
 	switch status {
 	case types.PodRunning, types.PodSucceeded:
@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+	"github.com/ueokande/logbook/pkg/types"
+)
+
+func newHarnessWithLines(t *testing.T, n int) *harness {
+	h := newHarness(t, 60, 10)
+	h.ui.AddPod("nginx", types.PodRunning)
+	h.ui.AddContainer("app")
+	h.ui.SelectPodAt(0)
+	h.ui.SelectContainerAt(0)
+	for i := 1; i <= n; i++ {
+		h.ui.AddPagerText(fmt.Sprintf("line %d", i), time.Time{})
+	}
+	return h
+}
+
+func TestUIPodList(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.ui.AddPod("nginx", types.PodRunning)
+	h.ui.AddPod("redis", types.PodPending)
+	h.ui.AddPod("mysql", types.PodFailed)
+	h.ui.SelectPodAt(0)
+
+	h.key(tcell.KeyCtrlN)
+	h.key(tcell.KeyCtrlP)
+	h.key(tcell.KeyCtrlP)
+	expected := []string{"nginx", "redis", "nginx", "mysql"}
+	if !reflect.DeepEqual(h.listener.pods, expected) {
+		t.Errorf("expected %v, actual %v", expected, h.listener.pods)
+	}
+
+	h.expectRow(0, "nginx│")
+	h.expectRow(2, "mysql│")
+	if _, _, attrs := h.style(0, 2).Decompose(); attrs&tcell.AttrReverse == 0 {
+		t.Error("the selected pod is not reversed")
+	}
+	if _, _, attrs := h.style(0, 0).Decompose(); attrs&tcell.AttrReverse != 0 {
+		t.Error("the unselected pod is reversed")
+	}
+	h.expectRow(-1, " 3 Pods ")
+
+	h.ui.DeletePod("redis")
+	h.expectRow(1, "mysql│")
+	h.expectRow(-1, " 2 Pods ")
+
+	h.ui.MarkPodDeleted("mysql")
+	h.expectRow(1, "mysql (deleted)│")
+	h.expectRow(-1, " 1 Pods ")
+	if _, _, attrs := h.style(0, 1).Decompose(); attrs&tcell.AttrReverse == 0 {
+		t.Error("the selected pod is not reversed")
+	}
+
+	h.ui.SetPodStatus("mysql", types.PodRunning)
+	h.expectRow(1, "mysql│")
+	h.expectRow(-1, " 2 Pods ")
+
+	h.ui.SelectPod("nginx")
+	h.ui.DeletePod("mysql")
+	if h.listener.pods[len(h.listener.pods)-1] != "nginx" {
+		t.Errorf("unexpected pods: %v", h.listener.pods)
+	}
+
+	// the virtual item is not counted as a pod
+	h.ui.AddPod("Events", types.PodRunning)
+	h.ui.MarkPodVirtual("Events")
+	h.ui.SetPodStatus("Events", types.PodFailed)
+	h.expectRow(-1, " 1 Pods ")
+	if fg, _, _ := h.style(0, 1).Decompose(); fg != tcell.ColorTeal {
+		t.Errorf("unexpected color of the virtual item: %v", fg)
+	}
+}
+
+func TestUINestedPods(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.ui.AddPod("Deployment/web", types.PodRunning)
+	h.ui.AddPod("redis", types.PodRunning)
+	h.ui.AddNestedPod("web-abcde", "Deployment/web", types.PodRunning)
+	h.ui.AddNestedPod("web-fghij", "Deployment/web", types.PodPending)
+	h.ui.SelectPod("redis")
+
+	h.expectRow(0, "Deployment/web│")
+	h.expectRow(1, "  web-abcde   │")
+	h.expectRow(2, "  web-fghij   │")
+	h.expectRow(3, "redis         │")
+
+	h.ui.MovePod("redis", "Deployment/web")
+	h.ui.MovePod("web-abcde", "")
+	h.expectRow(1, "  web-fghij")
+	h.expectRow(2, "  redis")
+	h.expectRow(3, "web-abcde")
+	if _, _, attrs := h.style(2, 2).Decompose(); attrs&tcell.AttrReverse == 0 {
+		t.Error("the selected pod is not reversed")
+	}
+
+	h.ui.ClearPods()
+	h.expectRow(-1, " 0 Pods ")
+	h.expectRow(0, "│")
+}
+
+func TestUITabs(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.ui.AddPod("nginx", types.PodRunning)
+	h.ui.AddContainer("init")
+	h.ui.AddContainer("app")
+	h.ui.AddContainer("sidecar")
+	h.ui.SelectContainerAt(0)
+
+	h.key(tcell.KeyTab)
+	h.key(tcell.KeyBacktab)
+	h.key(tcell.KeyBacktab)
+	h.key(tcell.KeyTab)
+	expected := []string{"init", "app", "init", "sidecar", "init"}
+	if !reflect.DeepEqual(h.listener.containers, expected) {
+		t.Errorf("expected %v, actual %v", expected, h.listener.containers)
+	}
+	h.expectRow(0, " init  app  sidecar")
+
+	h.ui.ClearContainers()
+	h.ui.AddContainer("redis")
+	if row := h.row(0); row != "nginx│ redis" {
+		t.Errorf("unexpected tabs: %q", row)
+	}
+}
+
+func TestUIPagerScroll(t *testing.T) {
+	h := newHarnessWithLines(t, 30)
+	h.expectRow(1, "│line 1")
+	h.expectRow(-1, " 0%")
+
+	h.typeText("jj")
+	h.expectRow(1, "│line 3")
+	h.typeText("k")
+	h.expectRow(1, "│line 2")
+
+	h.typeText("G")
+	h.expectRow(-2, "│line 30")
+	h.expectRow(-1, " 100%")
+
+	h.typeText("g")
+	h.expectRow(1, "│line 1")
+
+	// Ctrl-F and Ctrl-B scroll a page down and up
+	h.key(tcell.KeyCtrlF)
+	h.expectRow(1, "│line 9")
+	h.key(tcell.KeyCtrlB)
+	h.expectRow(1, "│line 1")
+}
+
+func TestUISearch(t *testing.T) {
+	h := newHarnessWithLines(t, 30)
+
+	h.typeText("/")
+	h.expectRow(-1, "/")
+	if len(h.ui.Widgets()) != 2 {
+		t.Fatalf("unexpected widgets: %d", len(h.ui.Widgets()))
+	}
+	h.typeText("line 25")
+	h.expectRow(-1, "/line 25")
+	h.key(tcell.KeyEnter)
+
+	if h.ui.pager.Keyword() != "line 25" {
+		t.Errorf("unexpected keyword: %q", h.ui.pager.Keyword())
+	}
+	h.expectRow(-1, "NORMAL")
+	y := h.findRow("│line 25")
+	if y < 0 {
+		t.Fatal("the found line is not shown")
+	}
+	if h.style(6, y) == h.style(6, y-1) {
+		t.Error("the keyword is not highlighted")
+	}
+
+	// the previous keyword is used for the empty input
+	h.typeText("g/")
+	h.key(tcell.KeyEnter)
+	if h.findRow("│line 25") < 0 {
+		t.Error("the found line is not shown")
+	}
+}
+
+func TestUICancelInput(t *testing.T) {
+	h := newHarnessWithLines(t, 3)
+
+	h.typeText("@")
+	h.expectRow(-1, "Go to time:")
+	h.key(tcell.KeyEscape)
+	h.expectRow(-1, "NORMAL")
+
+	// cancel twice, such as closing the input by multiple events
+	h.ui.cancelInput()
+	h.typeText("s")
+	h.ui.enterSaveInputMode()
+	h.key(tcell.KeyEscape)
+	if len(h.ui.Widgets()) != 2 {
+		t.Errorf("widgets are added again: %d", len(h.ui.Widgets()))
+	}
+	h.expectRow(-1, "NORMAL")
+	h.expectRow(-2, "│")
+}
+
+func TestUIFollowMode(t *testing.T) {
+	h := newHarnessWithLines(t, 3)
+
+	h.typeText("f")
+	h.expectRow(-1, "FOLLOW")
+	for i := 4; i <= 30; i++ {
+		h.ui.AddPagerText(fmt.Sprintf("line %d", i), time.Time{})
+	}
+	h.expectRow(-2, "│line 30")
+
+	// scrolling is disabled in follow mode
+	h.typeText("g")
+	h.expectRow(-2, "│line 30")
+
+	h.typeText("f")
+	h.expectRow(-1, "NORMAL")
+	h.typeText("g")
+	h.expectRow(1, "│line 1")
+
+	// the pager is cleared and follow mode is disabled on switching
+	h.typeText("f")
+	h.ui.ClearPager()
+	h.expectRow(-1, "NORMAL")
+	h.expectRow(1, "│")
+
+	// follow mode is enabled on switching if it is the default
+	h.ui.SetFollowByDefault(true)
+	h.typeText("f")
+	h.ui.ClearPager()
+	h.expectRow(-1, "FOLLOW")
+}
+
+func TestUIStatusBar(t *testing.T) {
+	h := newHarness(t, 60, 10)
+	h.ui.SetContext("kind", "default")
+	h.ui.AddPod("nginx", types.PodRunning)
+	h.expectRow(-1, " NORMAL  1 Pods ")
+	h.expectRow(-1, "kind/default")
+	h.expectRow(-1, "E:0 W:0")
+
+	h.ui.AddPagerText("ERROR something failed", time.Time{})
+	h.ui.AddPagerText("WARN something wrong", time.Time{})
+	h.ui.AddPagerText("ERROR something failed again", time.Time{})
+	h.expectRow(-1, "E:2 W:1")
+
+	// the line inheriting the level of the previous line is not counted
+	h.ui.AddPagerText("    at main.go:12", time.Time{})
+	h.expectRow(-1, "E:2 W:1")
+
+	h.ui.ShowMessage("hello")
+	h.expectRow(-1, "hello")
+	h.typeText("j")
+	if row := h.row(-1); strings.Contains(row, "hello") {
+		t.Errorf("the message is not cleared: %q", row)
+	}
+
+	h.typeText("q")
+	if !h.listener.quit {
+		t.Error("quit is not requested")
+	}
+}
@@ -0,0 +1,184 @@
+package widgets
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+var styleGutter = tcell.StyleDefault.Foreground(tcell.ColorGray)
+
+// TimestampFormat represents a format of the timestamps in the gutter
+type TimestampFormat int
+
+// The formats of the timestamps
+const (
+	TimestampLocal    TimestampFormat = iota // The time in local time zone
+	TimestampUTC                             // The time in UTC
+	TimestampRelative                        // The time elapsed from the line, such as "12s ago"
+)
+
+// ParseTimestampFormat returns the format of the timestamps by the name,
+// one of "local", "utc" or "relative"
+func ParseTimestampFormat(name string) (TimestampFormat, error) {
+	switch strings.ToLower(name) {
+	case "local":
+		return TimestampLocal, nil
+	case "utc":
+		return TimestampUTC, nil
+	case "relative":
+		return TimestampRelative, nil
+	}
+	return TimestampLocal, fmt.Errorf("unknown timestamp format %q, must be one of local, utc or relative", name)
+}
+
+const (
+	timestampLayoutLocal = "01-02 15:04:05.000"
+	timestampLayoutUTC   = "01-02 15:04:05.000Z"
+	timestampWidthRel    = 8
+)
+
+// SetGutter shows or hides the gutter on the left of the pager.  The gutter
+// shows line numbers and timestamps of the lines.
+func (w *Pager) SetGutter(show bool) {
+	w.gutter = show
+	w.Resize()
+	w.PostEventWidgetContent(w)
+}
+
+// Gutter returns true if the gutter is shown
+func (w *Pager) Gutter() bool {
+	return w.gutter
+}
+
+// SetTimestampFormat sets the format of the timestamps in the gutter
+func (w *Pager) SetTimestampFormat(format TimestampFormat) {
+	w.timestampFormat = format
+	w.Resize()
+	w.PostEventWidgetContent(w)
+}
+
+// TimestampFormat returns the format of the timestamps in the gutter
+func (w *Pager) TimestampFormat() TimestampFormat {
+	return w.timestampFormat
+}
+
+// FirstTime returns the oldest timestamp of the lines in the pager.  It
+// returns zero if the lines have no timestamps.
+func (w *Pager) FirstTime() time.Time {
+	return w.text.firstTime()
+}
+
+// ScrollToTime scrolls to the first line at or after the timestamp.  It
+// returns false if no lines found.
+func (w *Pager) ScrollToTime(timestamp time.Time) bool {
+	y := w.text.rowAtTime(timestamp)
+	if y < 0 {
+		return false
+	}
+	w.scrollTo(y)
+	w.PostEventWidgetContent(w)
+	return true
+}
+
+// calcGutterWidth returns the width of the gutter.  The column of the marks
+// is shown if lines are marked even if the gutter is hidden.
+func (w *Pager) calcGutterWidth() int {
+	var width int
+	if w.hasMarks() {
+		width += 2
+	}
+	if !w.gutter {
+		return width
+	}
+	width += w.lineNumberDigits() + 1
+	if w.text.HasTimestamps() {
+		width += timestampWidth(w.timestampFormat) + 1
+	}
+	return width
+}
+
+func (w *Pager) lineNumberDigits() int {
+	return len(strconv.Itoa(w.text.lineID(w.text.LineCount())))
+}
+
+func (w *Pager) drawGutter() {
+	if w.gutterWidth == 0 {
+		return
+	}
+	_, height := w.view.Size()
+	_, top, _, _ := w.viewport.GetVisible()
+	digits := w.lineNumberDigits()
+	marks := w.hasMarks()
+	now := time.Now()
+	for y := 0; y < height; y++ {
+		for x := 0; x < w.gutterWidth; x++ {
+			w.view.SetContent(x, y, ' ', nil, styleGutter)
+		}
+		line, col := w.text.rowPos(top + y)
+		if line < 0 || col > 0 {
+			continue
+		}
+
+		x := 0
+		if marks {
+			if m := w.markAt(line); m != 0 {
+				w.view.SetContent(x, y, m, nil, styleGutterMark)
+			}
+			x += 2
+		}
+		if !w.gutter {
+			continue
+		}
+		s := fmt.Sprintf("%*d", digits, w.text.lineID(line)+1)
+		if w.text.HasTimestamps() {
+			s += " " + formatTimestamp(w.text.lineTime(line), w.timestampFormat, now)
+		}
+		for _, c := range s {
+			w.view.SetContent(x, y, c, nil, styleGutter)
+			x++
+		}
+	}
+}
+
+func timestampWidth(format TimestampFormat) int {
+	switch format {
+	case TimestampUTC:
+		return len(timestampLayoutUTC)
+	case TimestampRelative:
+		return timestampWidthRel
+	}
+	return len(timestampLayoutLocal)
+}
+
+func formatTimestamp(t time.Time, format TimestampFormat, now time.Time) string {
+	width := timestampWidth(format)
+	if t.IsZero() {
+		return fmt.Sprintf("%*s", width, "")
+	}
+	switch format {
+	case TimestampUTC:
+		return t.UTC().Format(timestampLayoutUTC)
+	case TimestampRelative:
+		return fmt.Sprintf("%*s", width, formatDuration(now.Sub(t))+" ago")
+	}
+	return t.Local().Format(timestampLayoutLocal)
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		if d < 0 {
+			d = 0
+		}
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+}
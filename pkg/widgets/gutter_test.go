@@ -0,0 +1,93 @@
+package widgets
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestPagerGutter(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(40, 2)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.SetTimestampFormat(TimestampUTC)
+	p.SetGutter(true)
+	p.AppendLine("untimed")
+	if w := p.calcGutterWidth(); w != 2 {
+		t.Errorf("gutter width without timestamps: %d, want 2", w)
+	}
+
+	t0 := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	p.AppendTimedLine("timed", t0)
+	if w := p.calcGutterWidth(); w != 2+len(timestampLayoutUTC)+1 {
+		t.Errorf("gutter width with timestamps: %d", w)
+	}
+	rows := drawRows(screen, p)
+	if expected := "1" + strings.Repeat(" ", 21) + "untimed"; rows[0] != expected {
+		t.Errorf("unexpected row of the line without the timestamp: %q, want %q", rows[0], expected)
+	}
+	if expected := "2 06-01 12:00:00.000Z timed"; rows[1] != expected {
+		t.Errorf("unexpected row of the timed line: %q, want %q", rows[1], expected)
+	}
+
+	for i := 3; i <= 10; i++ {
+		p.AppendLine(fmt.Sprintf("line %d", i))
+	}
+	if w := p.calcGutterWidth(); w != 3+len(timestampLayoutUTC)+1 {
+		t.Errorf("gutter width with 10 lines: %d", w)
+	}
+
+	p.SetGutter(false)
+	if w := p.calcGutterWidth(); w != 0 {
+		t.Errorf("gutter width of the hidden gutter: %d", w)
+	}
+}
+
+func TestPagerScrollToTime(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(40, 1)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.AppendLine("untimed")
+	if p.ScrollToTime(time.Time{}) {
+		t.Error("ScrollToTime should fail on the lines without timestamps")
+	}
+
+	t0 := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+	p.AppendTimedLine("first", t0)
+	p.AppendLine("continued")
+	p.AppendTimedLine("second", t0.Add(2*time.Second))
+	if !p.FirstTime().Equal(t0) {
+		t.Errorf("unexpected first time: %v", p.FirstTime())
+	}
+
+	for _, c := range []struct {
+		time  time.Time
+		index int
+	}{
+		{time.Time{}, 1},
+		{t0.Add(-time.Hour), 1},
+		{t0, 1},
+		{t0.Add(time.Second), 3},
+	} {
+		p.ScrollToTop()
+		if !p.ScrollToTime(c.time) {
+			t.Errorf("ScrollToTime(%v) found no lines", c.time)
+			continue
+		}
+		if l := p.CurrentLine(); l != c.index {
+			t.Errorf("ScrollToTime(%v) scrolled to %d, want %d", c.time, l, c.index)
+		}
+	}
+	if p.ScrollToTime(t0.Add(time.Minute)) {
+		t.Error("ScrollToTime should fail after the last timestamp")
+	}
+}
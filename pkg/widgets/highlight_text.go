@@ -1,111 +1,580 @@
 package widgets
 
 import (
-	"strings"
+	"sort"
+	"time"
 
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
 	"github.com/mattn/go-runewidth"
+	"github.com/ueokande/logbook/pkg/ansi"
+	"github.com/ueokande/logbook/pkg/level"
 )
 
-type point struct {
-	x int
-	y int
+var (
+	styleHighlightCurrent = tcell.StyleDefault.Background(tcell.ColorYellow)
+	styleSelection        = tcell.StyleDefault.Reverse(true)
+
+	styleLineError = tcell.StyleDefault.Foreground(tcell.ColorRed)
+	styleLineWarn  = tcell.StyleDefault.Foreground(tcell.ColorYellow)
+	styleLineDebug = tcell.StyleDefault.Foreground(tcell.ColorGray)
+)
+
+// LineFormatter converts a line into the runes and their styles to be drawn.
+type LineFormatter func(line string) ([]rune, []tcell.Style)
+
+// FormatANSI is a LineFormatter which converts ANSI escape sequences in the
+// line into the styles.
+func FormatANSI(line string) ([]rune, []tcell.Style) {
+	return ansi.Parse(line, tcell.StyleDefault)
+}
+
+type textLine struct {
+	raw    string
+	runes  []rune
+	styles []tcell.Style
+	width  int
+	level  level.Level
+	time   time.Time
 }
 
-var styleHighlightCurrent = tcell.StyleDefault.Background(tcell.ColorYellow)
+// textRow is a row on the screen, which is a whole line or a part of the
+// wrapped line.
+type textRow struct {
+	line  int
+	start int
+	end   int
+}
 
-// HighlightText is a text widget with highlighted keyword
+type highlight struct {
+	line int
+	col  int
+}
+
+// HighlightText is a text widget with highlighted keyword.  The lines are
+// converted into the runes and the styles by the formatter, and the lines
+// lower than the minimum level are hidden.  The long lines are wrapped if
+// the wrap width is set.  The positions on the text, such as the positions
+// of highlights, are represented by the rows on the screen.
 type HighlightText struct {
-	highlights []int
+	lines      []textLine
+	rows       []textRow
+	width      int
+	wrapWidth  int
+	highlights []highlight
 	current    int
 	keyword    []rune
+	stripANSI  bool
+	formatter  LineFormatter
+	minLevel   level.Level
+	timestamps bool
+
+	// maxLines is the max count of the lines kept in the content.  The
+	// oldest lines are dropped if the count exceeds it.  offset is the
+	// count of the lines dropped, and offset+index is an absolute ID of the
+	// line which does not change by dropping lines.
+	maxLines int
+	offset   int
 
-	text views.Text
+	// selStart and selEnd are the first and the last lines of the
+	// selection if selecting is true
+	selecting bool
+	selStart  int
+	selEnd    int
+
+	view views.View
 	views.WidgetWatchers
 }
 
 // Draw draws the HighlightText.
 func (t *HighlightText) Draw() {
-	t.text.Draw()
+	if t.view == nil {
+		return
+	}
+	t.view.Fill(' ', tcell.StyleDefault)
+
+	top, right, bottom := 0, t.width-1, len(t.rows)-1
+	if v, ok := t.view.(interface {
+		GetVisible() (int, int, int, int)
+	}); ok {
+		_, top, right, bottom = v.GetVisible()
+	}
+	if top < 0 {
+		top = 0
+	}
+	if bottom >= len(t.rows) {
+		bottom = len(t.rows) - 1
+	}
+
+	var styles []tcell.Style
+	for y := top; y <= bottom; y++ {
+		row := t.rows[y]
+		if y == top || row.start == 0 {
+			styles = t.lineStyles(row.line)
+		}
+		line := t.lines[row.line]
+
+		var x int
+		for i := row.start; i < row.end; i++ {
+			c := line.runes[i]
+			t.view.SetContent(x, y, c, nil, styles[i])
+			x += runewidth.RuneWidth(c)
+		}
+		if t.selected(row.line) {
+			for ; x <= right; x++ {
+				t.view.SetContent(x, y, ' ', nil, styleSelection)
+			}
+		}
+	}
+}
+
+// lineStyles returns the styles of the runes in the line, including the
+// highlights of the keyword.
+func (t *HighlightText) lineStyles(index int) []tcell.Style {
+	line := t.lines[index]
+	styles := make([]tcell.Style, len(line.runes))
+	for i := range styles {
+		switch {
+		case t.stripANSI:
+			styles[i] = tcell.StyleDefault
+		case line.styles[i] == tcell.StyleDefault:
+			styles[i] = levelStyle(line.level)
+		default:
+			styles[i] = line.styles[i]
+		}
+	}
+
+	if t.selected(index) {
+		for i := range styles {
+			styles[i] = styles[i].Reverse(true)
+		}
+	}
+
+	h := sort.Search(len(t.highlights), func(i int) bool {
+		return t.highlights[i].line >= index
+	})
+	for ; h < len(t.highlights) && t.highlights[h].line == index; h++ {
+		for i := range t.keyword {
+			col := t.highlights[h].col + i
+			if h == t.current {
+				styles[col] = styleHighlightCurrent
+			} else {
+				styles[col] = styles[col].Reverse(true)
+			}
+		}
+	}
+	return styles
 }
 
 // Size returns the width and height of the HighlightText
 func (t *HighlightText) Size() (int, int) {
-	return t.text.Size()
+	return t.width, len(t.rows)
 }
 
 // SetView sets the view for the HighlightText
 func (t *HighlightText) SetView(view views.View) {
-	t.text.SetView(view)
+	t.view = view
 }
 
 // HandleEvent implements a tcell.EventHandler
 func (t *HighlightText) HandleEvent(ev tcell.Event) bool {
-	return t.text.HandleEvent(ev)
+	return false
 }
 
-// AppendLine appends the line into the content
-func (t *HighlightText) AppendLine(line string) {
-	text := t.text.Text()
-	if len(text) > 0 {
-		text += "\n"
+// AppendLine appends the line and its timestamp into the content.  The
+// timestamp can be zero.  The level of the line is detected from the line, or
+// inherited from the previous line if the level is not detected, such as
+// lines of a stack trace.  It returns the level detected from the line, which
+// is level.Unknown if the level is inherited.
+func (t *HighlightText) AppendLine(line string, timestamp time.Time) level.Level {
+	l := t.format(line)
+	l.time = timestamp
+	if !timestamp.IsZero() {
+		t.timestamps = true
 	}
-	text += line
-	t.text.SetText(text)
+	detected := level.Detect(line)
+	l.level = detected
+	if l.level == level.Unknown && len(t.lines) > 0 {
+		l.level = t.lines[len(t.lines)-1].level
+	}
+	t.lines = append(t.lines, l)
 
-	t.resetHighlights()
+	index := len(t.lines) - 1
+	if t.visible(index) {
+		t.appendRows(index)
+		t.findHighlights(index)
+	}
 
 	t.PostEventWidgetContent(t)
+	return detected
+}
+
+// SetMaxLines sets the max count of the lines kept in the content.  The lines
+// are not dropped if the max is 0.
+func (t *HighlightText) SetMaxLines(max int) {
+	if max < 0 {
+		max = 0
+	}
+	t.maxLines = max
+}
+
+// trim drops the oldest lines if the count of the lines exceeds the max count
+// by a tenth, so that lines are dropped in a batch.  It returns the count of
+// the lines and the rows dropped.
+func (t *HighlightText) trim() (int, int) {
+	if t.maxLines == 0 || len(t.lines) <= t.maxLines+t.maxLines/10 {
+		return 0, 0
+	}
+	n := len(t.lines) - t.maxLines
+	t.lines = append([]textLine(nil), t.lines[n:]...)
+	t.offset += n
+
+	r := sort.Search(len(t.rows), func(i int) bool {
+		return t.rows[i].line >= n
+	})
+	rows := make([]textRow, 0, len(t.rows)-r)
+	for _, row := range t.rows[r:] {
+		row.line -= n
+		rows = append(rows, row)
+	}
+	t.rows = rows
+
+	h := sort.Search(len(t.highlights), func(i int) bool {
+		return t.highlights[i].line >= n
+	})
+	highlights := make([]highlight, 0, len(t.highlights)-h)
+	for _, hl := range t.highlights[h:] {
+		hl.line -= n
+		highlights = append(highlights, hl)
+	}
+	t.highlights = highlights
+	if t.current >= 0 {
+		t.current -= h
+		if t.current < 0 {
+			t.current = -1
+		}
+	}
+
+	if t.selecting {
+		t.selStart -= n
+		t.selEnd -= n
+		if t.selStart < 0 {
+			t.selStart = 0
+		}
+		if t.selEnd < 0 {
+			t.selecting = false
+		}
+	}
+	return n, r
+}
+
+// lineID returns the absolute ID of the line at the index
+func (t *HighlightText) lineID(index int) int {
+	return t.offset + index
+}
+
+// lineIndex returns the index of the line by the absolute ID.  It returns -1
+// if the line is dropped or not exists.
+func (t *HighlightText) lineIndex(id int) int {
+	index := id - t.offset
+	if index < 0 || index >= len(t.lines) {
+		return -1
+	}
+	return index
+}
+
+// SetFormatter sets the formatter of the lines, and formats the current
+// lines again.  The lines are formatted by FormatANSI if the formatter is
+// nil.
+func (t *HighlightText) SetFormatter(f LineFormatter) {
+	t.formatter = f
+	for i, l := range t.lines {
+		nl := t.format(l.raw)
+		nl.level = l.level
+		nl.time = l.time
+		t.lines[i] = nl
+	}
+	t.resetRows()
+	t.SetKeyword(string(t.keyword))
+}
+
+// SetMinLevel sets the minimum level of the lines to be shown.  All lines
+// are shown if the level is level.Unknown.
+func (t *HighlightText) SetMinLevel(l level.Level) {
+	t.minLevel = l
+	t.resetRows()
+	t.SetKeyword(string(t.keyword))
+}
+
+// MinLevel returns the minimum level of the lines to be shown.
+func (t *HighlightText) MinLevel() level.Level {
+	return t.minLevel
+}
+
+// SetWrapWidth sets the width to wrap long lines.  The lines are not wrapped
+// if the width is 0.
+func (t *HighlightText) SetWrapWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	if width == t.wrapWidth {
+		return
+	}
+	t.wrapWidth = width
+	t.resetRows()
+}
+
+// WrapWidth returns the width to wrap long lines.  It returns 0 if the lines
+// are not wrapped.
+func (t *HighlightText) WrapWidth() int {
+	return t.wrapWidth
+}
+
+// Line returns the original line at the index.  The index counts hidden
+// lines and wrapped lines as one.
+func (t *HighlightText) Line(index int) string {
+	return t.lines[index].raw
+}
+
+// rowPos returns the index of the line and the offset of the rune in the
+// line at the row.
+func (t *HighlightText) rowPos(row int) (int, int) {
+	if row < 0 || row >= len(t.rows) {
+		return -1, 0
+	}
+	return t.rows[row].line, t.rows[row].start
+}
+
+// rowAt returns the row containing the rune at col in the line.  It returns
+// the row of the next line if the line is hidden.
+func (t *HighlightText) rowAt(line, col int) int {
+	i := sort.Search(len(t.rows), func(i int) bool {
+		return t.rows[i].line >= line
+	})
+	for i+1 < len(t.rows) && t.rows[i+1].line == line && t.rows[i+1].start <= col {
+		i++
+	}
+	if i >= len(t.rows) {
+		return len(t.rows) - 1
+	}
+	return i
+}
+
+// LineCount returns the count of the lines including hidden lines
+func (t *HighlightText) LineCount() int {
+	return len(t.lines)
+}
+
+// HasTimestamps returns true if the content has a line with the timestamp
+func (t *HighlightText) HasTimestamps() bool {
+	return t.timestamps
+}
+
+func (t *HighlightText) lineTime(index int) time.Time {
+	return t.lines[index].time
+}
+
+// snapshot returns the lines from start to end (exclusive) in the content.
+// It returns only the lines shown if the visibleOnly is true.
+func (t *HighlightText) snapshot(start, end int, visibleOnly bool) []Line {
+	lines := make([]Line, 0, end-start)
+	for i := start; i < end; i++ {
+		if visibleOnly && !t.visible(i) {
+			continue
+		}
+		lines = append(lines, Line{Text: t.lines[i].raw, Time: t.lines[i].time})
+	}
+	return lines
+}
+
+// firstTime returns the oldest timestamp in the lines.  It returns zero if
+// the lines have no timestamps.
+func (t *HighlightText) firstTime() time.Time {
+	for _, l := range t.lines {
+		if !l.time.IsZero() {
+			return l.time
+		}
+	}
+	return time.Time{}
+}
+
+// rowAtTime returns the first row of the line at or after the timestamp.  It
+// returns -1 if no lines found.
+func (t *HighlightText) rowAtTime(timestamp time.Time) int {
+	for y, row := range t.rows {
+		lt := t.lines[row.line].time
+		if row.start == 0 && !lt.IsZero() && !lt.Before(timestamp) {
+			return y
+		}
+	}
+	return -1
+}
+
+func (t *HighlightText) format(line string) textLine {
+	f := t.formatter
+	if f == nil {
+		f = FormatANSI
+	}
+	runes, styles := f(line)
+	return textLine{
+		raw:    line,
+		runes:  runes,
+		styles: styles,
+		width:  runewidth.StringWidth(string(runes)),
+	}
+}
+
+func (t *HighlightText) selected(index int) bool {
+	return t.selecting && t.selStart <= index && index <= t.selEnd
+}
+
+// setSelection selects lines between start and end.  The start can be larger
+// than the end.
+func (t *HighlightText) setSelection(start, end int) {
+	if start > end {
+		start, end = end, start
+	}
+	t.selecting = true
+	t.selStart, t.selEnd = start, end
+	t.PostEventWidgetContent(t)
+}
+
+func (t *HighlightText) clearSelection() {
+	t.selecting = false
+	t.PostEventWidgetContent(t)
+}
+
+// nextLine returns the index of the line shown delta lines after the line.
+// It moves backward if the delta is negative, and stops at the first or the
+// last line shown.
+func (t *HighlightText) nextLine(line, delta int) int {
+	step := 1
+	if delta < 0 {
+		step, delta = -1, -delta
+	}
+	for i := line + step; delta > 0 && i >= 0 && i < len(t.lines); i += step {
+		if t.visible(i) {
+			line = i
+			delta--
+		}
+	}
+	return line
+}
+
+func (t *HighlightText) visible(index int) bool {
+	return t.minLevel == level.Unknown || t.lines[index].level >= t.minLevel
+}
+
+func (t *HighlightText) resetRows() {
+	t.rows = t.rows[:0]
+	t.width = 0
+	for i := range t.lines {
+		if t.visible(i) {
+			t.appendRows(i)
+		}
+	}
+	t.PostEventWidgetContent(t)
+}
+
+// appendRows appends the rows of the line, which are wrapped by the wrap
+// width.
+func (t *HighlightText) appendRows(index int) {
+	line := t.lines[index]
+	if t.wrapWidth == 0 {
+		t.rows = append(t.rows, textRow{line: index, start: 0, end: len(line.runes)})
+		if line.width > t.width {
+			t.width = line.width
+		}
+		return
+	}
+
+	t.width = t.wrapWidth
+	start, x := 0, 0
+	for i, c := range line.runes {
+		w := runewidth.RuneWidth(c)
+		if x+w > t.wrapWidth && i > start {
+			t.rows = append(t.rows, textRow{line: index, start: start, end: i})
+			start, x = i, 0
+		}
+		x += w
+	}
+	t.rows = append(t.rows, textRow{line: index, start: start, end: len(line.runes)})
 }
 
 // ClearText clears current content and highlights
 func (t *HighlightText) ClearText() {
-	t.text.SetText("")
+	t.lines = nil
+	t.rows = nil
+	t.width = 0
+	t.timestamps = false
 	t.keyword = nil
 	t.current = -1
 	t.highlights = nil
+	t.selecting = false
+	t.offset = 0
+}
+
+// SetStripANSI sets whether the styles by ANSI escape sequences are stripped
+// or not on drawing.
+func (t *HighlightText) SetStripANSI(strip bool) {
+	t.stripANSI = strip
+	t.PostEventWidgetContent(t)
+}
+
+// StripANSI returns true if the styles by ANSI escape sequences are stripped.
+func (t *HighlightText) StripANSI() bool {
+	return t.stripANSI
 }
 
 // SetKeyword sets the keyword to be highlighted in the content
 func (t *HighlightText) SetKeyword(keyword string) {
 	t.keyword = []rune(keyword)
 	t.current = -1
-	t.text.SetStyle(t.text.Style())
+	t.highlights = nil
 	if len(keyword) == 0 {
 		return
 	}
 
-	t.resetHighlights()
+	for i := range t.lines {
+		if t.visible(i) {
+			t.findHighlights(i)
+		}
+	}
 	t.PostEventWidgetContent(t)
 }
 
-func (t *HighlightText) resetHighlights() {
-	t.highlights = nil
-
-	str := t.text.Text()
-	keyword := string(t.keyword)
-	var x int
-	for len(keyword) > 0 {
-		i := strings.Index(str, keyword)
-		if i == -1 {
-			break
+func (t *HighlightText) findHighlights(index int) {
+	if len(t.keyword) == 0 {
+		return
+	}
+	runes := t.lines[index].runes
+	for col := 0; col+len(t.keyword) <= len(runes); col++ {
+		if hasPrefix(runes[col:], t.keyword) {
+			t.highlights = append(t.highlights, highlight{line: index, col: col})
+			col += len(t.keyword) - 1
 		}
-		start := len([]rune(str[:i])) + x
-		t.highlights = append(t.highlights, start)
-		str = str[i+len(keyword):]
-		x += i + len(keyword)
 	}
+}
 
-	for i, start := range t.highlights {
-		style := t.text.Style().Reverse(true)
-		if i == t.current {
-			style = styleHighlightCurrent
-		}
-		for offset := range t.keyword {
-			t.text.SetStyleAt(start+offset, style)
+func hasPrefix(s, prefix []rune) bool {
+	for i := range prefix {
+		if s[i] != prefix[i] {
+			return false
 		}
 	}
+	return true
+}
+
+func levelStyle(l level.Level) tcell.Style {
+	switch l {
+	case level.Error:
+		return styleLineError
+	case level.Warn:
+		return styleLineWarn
+	case level.Debug, level.Trace:
+		return styleLineDebug
+	}
+	return tcell.StyleDefault
 }
 
 // Keyword returns the current keyword in the content
@@ -115,7 +584,6 @@ func (t *HighlightText) Keyword() string {
 
 // Resize is called when the View changes sizes.
 func (t *HighlightText) Resize() {
-	t.text.Resize()
 }
 
 // HighlightPos returns the position (x, y) of highlighted text in the content
@@ -124,19 +592,10 @@ func (t *HighlightText) HighlightPos(index int) (int, int) {
 		panic("index out of range")
 	}
 
-	start := t.highlights[index]
-	var x, y int
-	for i, c := range []rune(t.text.Text()) {
-		if i == start {
-			break
-		}
-		if c == '\n' {
-			x = 0
-			y++
-			continue
-		}
-		x += runewidth.RuneWidth(c)
-	}
+	h := t.highlights[index]
+	y := t.rowAt(h.line, h.col)
+	row := t.rows[y]
+	x := runewidth.StringWidth(string(t.lines[h.line].runes[row.start:h.col]))
 	return x, y
 }
 
@@ -147,11 +606,10 @@ func (t *HighlightText) HighlightCount() int {
 
 // ActivateHighlight makes the highlighted keyword active (focused)
 func (t *HighlightText) ActivateHighlight(index int) {
-	if index < 0 || index > len(t.highlights) {
+	if index < 0 || index >= len(t.highlights) {
 		panic("index out of range")
 	}
 	t.current = index
-	t.resetHighlights()
 	t.PostEventWidgetContent(t)
 }
 
@@ -34,6 +34,13 @@ func NewListView() *ListView {
 // unique in the list view.  It panics when the text is already exists in the
 // list
 func (w *ListView) AddItem(text string, style tcell.Style) {
+	w.InsertItem(len(w.items), text, style)
+}
+
+// InsertItem inserts a new item with the text and its style at the index.
+// The text must be unique in the list view.  It panics when the text is
+// already exists in the list
+func (w *ListView) InsertItem(index int, text string, style tcell.Style) {
 	if w.getItemIndex(text) != -1 {
 		panic("item " + text + " already exists")
 	}
@@ -49,6 +56,11 @@ func (w *ListView) AddItem(text string, style tcell.Style) {
 
 	item := item{name: text, text: t, view: v}
 	w.items = append(w.items, item)
+	copy(w.items[index+1:], w.items[index:])
+	w.items[index] = item
+	if w.selected >= index {
+		w.selected++
+	}
 
 	w.changed = true
 	w.layout()
@@ -63,12 +75,30 @@ func (w *ListView) SetStyle(text string, style tcell.Style) {
 		panic("item " + text + " not fount")
 	}
 
+	if idx == w.selected {
+		style = style.Reverse(true)
+	}
 	w.items[idx].text.SetStyle(style)
 	w.changed = true
 	w.layout()
 	w.PostEventWidgetContent(w)
 }
 
+// SetLabel updates the label shown for the item with the text, such as to
+// decorate the item.  The item is still identified by the text.  It panics
+// when the text does not exist in the list.
+func (w *ListView) SetLabel(text, label string) {
+	idx := w.getItemIndex(text)
+	if idx == -1 {
+		panic("item " + text + " not fount")
+	}
+
+	w.items[idx].text.SetText(label)
+	w.changed = true
+	w.layout()
+	w.PostEventWidgetContent(w)
+}
+
 // DeleteItem deletes a item with the text.  It panics when the text does not
 // exist in the list
 func (w *ListView) DeleteItem(text string) {
@@ -79,6 +109,59 @@ func (w *ListView) DeleteItem(text string) {
 	item := w.items[idx]
 	item.text.Unwatch(w)
 	w.items = append(w.items[:idx], w.items[idx+1:]...)
+
+	// keep the selected item selected, or select nothing if it is deleted
+	if idx == w.selected {
+		w.selected = -1
+	} else if idx < w.selected {
+		w.selected--
+	}
+
+	w.changed = true
+	w.layout()
+	w.PostEventWidgetContent(w)
+}
+
+// MoveItem moves the item with the text to the index.  The selected item is
+// kept selected.  It panics when the text does not exist in the list.
+func (w *ListView) MoveItem(text string, index int) {
+	idx := w.getItemIndex(text)
+	if idx == -1 {
+		panic("item " + text + " not fount")
+	}
+	selected := w.Selected()
+
+	item := w.items[idx]
+	w.items = append(w.items[:idx], w.items[idx+1:]...)
+	w.items = append(w.items, item)
+	copy(w.items[index+1:], w.items[index:])
+	w.items[index] = item
+	if len(selected) > 0 {
+		w.selected = w.getItemIndex(selected)
+	}
+
+	w.changed = true
+	w.layout()
+	w.PostEventWidgetContent(w)
+}
+
+// Clear deletes all items in the list
+func (w *ListView) Clear() {
+	for _, item := range w.items {
+		item.text.Unwatch(w)
+	}
+	w.items = nil
+	w.selected = -1
+
+	w.changed = true
+	w.layout()
+	w.PostEventWidgetContent(w)
+}
+
+// ItemIndex returns the index of the item with the text.  It returns -1 if
+// the text does not exist in the list.
+func (w *ListView) ItemIndex(text string) int {
+	return w.getItemIndex(text)
 }
 
 // ItemCount returns the count of the items.
@@ -95,6 +178,25 @@ func (w *ListView) getItemIndex(name string) int {
 	return -1
 }
 
+// Selected returns the text of the selected item.  It returns an empty
+// string if no items are selected.
+func (w *ListView) Selected() string {
+	if w.selected < 0 {
+		return ""
+	}
+	return w.items[w.selected].name
+}
+
+// Select selects the item with the text.  It does nothing if the text does
+// not exist in the list.
+func (w *ListView) Select(text string) {
+	idx := w.getItemIndex(text)
+	if idx == -1 {
+		return
+	}
+	w.SelectAt(idx)
+}
+
 // SelectNext selects next item of the current
 func (w *ListView) SelectNext() {
 	index := w.selected + 1
@@ -118,13 +220,13 @@ func (w *ListView) SelectAt(index int) {
 	if index == w.selected {
 		return
 	}
+	if index < 0 || index >= len(w.items) {
+		return
+	}
 	if w.selected >= 0 {
 		i := w.items[w.selected]
 		i.text.SetStyle(i.text.Style().Reverse(false))
 	}
-	if index < 0 || index >= len(w.items) {
-		return
-	}
 
 	w.selected = index
 	i := w.items[index]
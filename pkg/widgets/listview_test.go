@@ -0,0 +1,96 @@
+package widgets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestListViewSelect(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(10, 5)
+
+	w := NewListView()
+	w.SetView(screen)
+	r := &selectionRecorder{}
+	w.Watch(r)
+	for _, name := range []string{"nginx", "redis", "mysql"} {
+		w.AddItem(name, tcell.StyleDefault)
+	}
+
+	w.SelectAt(0)
+	w.SelectPrev()
+	w.SelectNext()
+	w.SelectNext()
+
+	expected := []string{"nginx", "mysql", "nginx", "redis"}
+	if !reflect.DeepEqual(r.names, expected) {
+		t.Errorf("expected %v, actual %v", expected, r.names)
+	}
+}
+
+func TestListViewDeleteItem(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(10, 5)
+
+	w := NewListView()
+	w.SetView(screen)
+	r := &selectionRecorder{}
+	w.Watch(r)
+	for _, name := range []string{"nginx", "redis", "mysql"} {
+		w.AddItem(name, tcell.StyleDefault)
+	}
+
+	w.SelectAt(2)
+	w.DeleteItem("nginx")
+	if w.Selected() != "mysql" {
+		t.Errorf("unexpected selected item: %q", w.Selected())
+	}
+	w.SelectNext()
+
+	w.DeleteItem("redis")
+	if w.Selected() != "" {
+		t.Errorf("unexpected selected item: %q", w.Selected())
+	}
+	w.SelectAt(0)
+	w.Select("postgres")
+
+	expected := []string{"mysql", "redis", "mysql"}
+	if !reflect.DeepEqual(r.names, expected) {
+		t.Errorf("expected %v, actual %v", expected, r.names)
+	}
+}
+
+func TestListViewInsertAndMoveItem(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(10, 5)
+
+	w := NewListView()
+	w.SetView(screen)
+	for _, name := range []string{"nginx", "redis"} {
+		w.AddItem(name, tcell.StyleDefault)
+	}
+	w.SelectAt(1)
+
+	w.InsertItem(0, "mysql", tcell.StyleDefault)
+	w.MoveItem("nginx", 2)
+	var names []string
+	for _, name := range []string{"mysql", "redis", "nginx"} {
+		names = append(names, name)
+		if i := w.ItemIndex(name); i != len(names)-1 {
+			t.Errorf("unexpected index of %s: %d", name, i)
+		}
+	}
+	if w.Selected() != "redis" {
+		t.Errorf("unexpected selected item: %q", w.Selected())
+	}
+
+	w.Clear()
+	if w.ItemCount() != 0 || w.Selected() != "" {
+		t.Errorf("items are not cleared: %d, %q", w.ItemCount(), w.Selected())
+	}
+}
@@ -0,0 +1,192 @@
+package widgets
+
+import (
+	"sort"
+
+	"github.com/gdamore/tcell"
+)
+
+var styleGutterMark = tcell.StyleDefault.Foreground(tcell.ColorYellow).Bold(true)
+
+// unnamedMark is the character of an unnamed bookmark in the gutter
+const unnamedMark = '*'
+
+// Bookmark is a marked line in the pager
+type Bookmark struct {
+	// Name is the name of the mark, or 0 for the unnamed bookmark
+	Name rune
+
+	// Number is the line number, which is not changed by dropping old lines
+	Number int
+
+	Line
+}
+
+// SetMark sets the mark by the name on the line on the top of the view.  It
+// returns false if the pager has no lines.
+func (w *Pager) SetMark(name rune) bool {
+	id, ok := w.topLineID()
+	if !ok {
+		return false
+	}
+	if w.marks == nil {
+		w.marks = make(map[rune]int)
+	}
+	w.marks[name] = id
+	w.updateGutterWidth()
+	return true
+}
+
+// JumpToMark scrolls to the line marked by the name.  It returns false if the
+// mark is not set or the line has been dropped.
+func (w *Pager) JumpToMark(name rune) bool {
+	id, ok := w.marks[name]
+	if !ok {
+		return false
+	}
+	return w.ScrollToLine(id + 1)
+}
+
+// ToggleBookmark adds or removes the unnamed bookmark on the line on the top
+// of the view.  It returns true if the bookmark is added.
+func (w *Pager) ToggleBookmark() bool {
+	id, ok := w.topLineID()
+	if !ok {
+		return false
+	}
+	i := sort.SearchInts(w.bookmarks, id)
+	if i < len(w.bookmarks) && w.bookmarks[i] == id {
+		w.bookmarks = append(w.bookmarks[:i], w.bookmarks[i+1:]...)
+		w.updateGutterWidth()
+		return false
+	}
+	w.bookmarks = append(w.bookmarks, 0)
+	copy(w.bookmarks[i+1:], w.bookmarks[i:])
+	w.bookmarks[i] = id
+	w.updateGutterWidth()
+	return true
+}
+
+// NextBookmark scrolls to the next marked line below the top of the view.  It
+// returns false if no marked lines found.
+func (w *Pager) NextBookmark() bool {
+	top, ok := w.topLineID()
+	if !ok {
+		return false
+	}
+	for _, b := range w.Bookmarks() {
+		if b.Number-1 > top && w.ScrollToLine(b.Number) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrevBookmark scrolls to the previous marked line above the top of the
+// view.  It returns false if no marked lines found.
+func (w *Pager) PrevBookmark() bool {
+	top, ok := w.topLineID()
+	if !ok {
+		return false
+	}
+	bookmarks := w.Bookmarks()
+	for i := len(bookmarks) - 1; i >= 0; i-- {
+		if bookmarks[i].Number-1 < top && w.ScrollToLine(bookmarks[i].Number) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bookmarks returns the named marks and the unnamed bookmarks in order of the
+// line number.
+func (w *Pager) Bookmarks() []Bookmark {
+	var bookmarks []Bookmark
+	add := func(name rune, id int) {
+		index := w.text.lineIndex(id)
+		if index < 0 {
+			return
+		}
+		lines := w.text.snapshot(index, index+1, false)
+		bookmarks = append(bookmarks, Bookmark{Name: name, Number: id + 1, Line: lines[0]})
+	}
+	for name, id := range w.marks {
+		add(name, id)
+	}
+	for _, id := range w.bookmarks {
+		add(0, id)
+	}
+	sort.Slice(bookmarks, func(i, j int) bool {
+		if bookmarks[i].Number != bookmarks[j].Number {
+			return bookmarks[i].Number < bookmarks[j].Number
+		}
+		return bookmarks[i].Name < bookmarks[j].Name
+	})
+	return bookmarks
+}
+
+// ScrollToLine scrolls to the line by the line number.  It returns false if
+// the line has been dropped.
+func (w *Pager) ScrollToLine(number int) bool {
+	index := w.text.lineIndex(number - 1)
+	if index < 0 {
+		return false
+	}
+	w.scrollTo(w.text.rowAt(index, 0))
+	w.PostEventWidgetContent(w)
+	return true
+}
+
+// topLineID returns the absolute ID of the line on the top of the view
+func (w *Pager) topLineID() (int, bool) {
+	line := w.CurrentLine()
+	if line < 0 {
+		return 0, false
+	}
+	return w.text.lineID(line), true
+}
+
+// markAt returns the character of the mark on the line at the index.  It
+// returns 0 if the line is not marked.
+func (w *Pager) markAt(index int) rune {
+	id := w.text.lineID(index)
+	var mark rune
+	for name, mid := range w.marks {
+		if mid == id && (mark == 0 || name < mark) {
+			mark = name
+		}
+	}
+	if mark != 0 {
+		return mark
+	}
+	i := sort.SearchInts(w.bookmarks, id)
+	if i < len(w.bookmarks) && w.bookmarks[i] == id {
+		return unnamedMark
+	}
+	return 0
+}
+
+func (w *Pager) hasMarks() bool {
+	return len(w.marks) > 0 || len(w.bookmarks) > 0
+}
+
+// pruneMarks removes the marks on the dropped lines
+func (w *Pager) pruneMarks() {
+	for name, id := range w.marks {
+		if w.text.lineIndex(id) < 0 {
+			delete(w.marks, name)
+		}
+	}
+	i := 0
+	for i < len(w.bookmarks) && w.text.lineIndex(w.bookmarks[i]) < 0 {
+		i++
+	}
+	w.bookmarks = w.bookmarks[i:]
+}
+
+func (w *Pager) updateGutterWidth() {
+	if w.gutterWidth != w.calcGutterWidth() {
+		w.Resize()
+	}
+	w.PostEventWidgetContent(w)
+}
@@ -0,0 +1,62 @@
+package widgets
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+func TestPagerMarks(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(40, 5)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.SetMaxLines(20)
+	for i := 1; i <= 20; i++ {
+		p.AppendLine(fmt.Sprintf("line %d", i))
+	}
+
+	p.ScrollToLine(3)
+	p.SetMark('a')
+	p.ScrollToLine(5)
+	p.ToggleBookmark()
+
+	for i := 21; i <= 22; i++ {
+		p.AppendLine(fmt.Sprintf("line %d", i))
+	}
+	if p.LineCount() != 22 {
+		t.Fatalf("lines should not be dropped until exceeding by a tenth: %d", p.LineCount())
+	}
+	// lines 1-3 are dropped, and lines 4-23 are kept
+	p.AppendLine("line 23")
+	if p.LineCount() != 20 {
+		t.Fatalf("unexpected line count: %d", p.LineCount())
+	}
+
+	bookmarks := p.Bookmarks()
+	if len(bookmarks) != 1 || bookmarks[0].Number != 5 || bookmarks[0].Text != "line 5" {
+		t.Errorf("unexpected bookmarks: %+v", bookmarks)
+	}
+	if p.JumpToMark('a') {
+		t.Error("mark on the dropped line should be removed")
+	}
+
+	p.ScrollToLine(10)
+	p.SetMark('b')
+	p.ScrollToTop()
+	if !p.JumpToMark('b') {
+		t.Fatal("mark 'b' not found")
+	}
+	if l := p.Line(p.CurrentLine()); l != "line 10" {
+		t.Errorf("jumped to %q", l)
+	}
+	if !p.PrevBookmark() || p.Line(p.CurrentLine()) != "line 5" {
+		t.Errorf("PrevBookmark jumped to %q", p.Line(p.CurrentLine()))
+	}
+	if !p.NextBookmark() || p.Line(p.CurrentLine()) != "line 10" {
+		t.Errorf("NextBookmark jumped to %q", p.Line(p.CurrentLine()))
+	}
+}
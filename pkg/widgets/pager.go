@@ -1,10 +1,22 @@
 package widgets
 
 import (
+	"time"
+
 	"github.com/gdamore/tcell"
 	"github.com/gdamore/tcell/views"
+	"github.com/ueokande/logbook/pkg/level"
 )
 
+// Line is a line in the pager
+type Line struct {
+	// Text is the original text of the line
+	Text string
+
+	// Time is the timestamp of the line.  It can be zero.
+	Time time.Time
+}
+
 // Pager is a Widget with the text and its view port.  It provides a scrollable
 // view if the content size is larger than the actual view.
 type Pager struct {
@@ -12,6 +24,18 @@ type Pager struct {
 	viewport  views.ViewPort
 	text      HighlightText
 	highlight string
+	wrap      bool
+
+	gutter          bool
+	gutterWidth     int
+	timestampFormat TimestampFormat
+
+	selecting bool
+	selAnchor int
+	selCursor int
+
+	marks     map[rune]int
+	bookmarks []int
 
 	views.WidgetWatchers
 }
@@ -25,11 +49,48 @@ func NewPager() *Pager {
 
 // AppendLine adds the line into the pager
 func (w *Pager) AppendLine(line string) {
-	w.text.AppendLine(line)
+	w.AppendTimedLine(line, time.Time{})
+}
 
-	width, height := w.text.Size()
-	w.viewport.SetContentSize(width, height, true)
-	w.viewport.ValidateView()
+// AppendTimedLine adds the line with its timestamp into the pager.  The
+// timestamp is shown in the gutter.  It returns the level detected from the
+// line, which is level.Unknown if the line inherits the level of the previous
+// line.
+func (w *Pager) AppendTimedLine(line string, timestamp time.Time) level.Level {
+	l := w.text.AppendLine(line, timestamp)
+	if lines, rows := w.text.trim(); lines > 0 {
+		w.trimmed(lines, rows)
+	}
+	if w.gutterWidth != w.calcGutterWidth() {
+		w.Resize()
+	}
+	w.updateContentSize()
+	return l
+}
+
+// SetMaxLines sets the max count of the lines kept in the pager.  The oldest
+// lines are dropped if the count exceeds the max, and the marks on them are
+// removed.  The lines are never dropped if the max is 0.
+func (w *Pager) SetMaxLines(max int) {
+	w.text.SetMaxLines(max)
+}
+
+// trimmed updates the selection, the marks and the scroll position after the
+// lines and the rows are dropped from the head of the content.
+func (w *Pager) trimmed(lines, rows int) {
+	if w.selecting {
+		w.selAnchor -= lines
+		w.selCursor -= lines
+		if w.selAnchor < 0 {
+			w.selAnchor = 0
+		}
+		if w.selCursor < 0 {
+			w.selCursor = 0
+		}
+		w.selecting = w.text.selecting
+	}
+	w.pruneMarks()
+	w.viewport.ScrollUp(rows)
 }
 
 // ScrollDown scrolls down by one line on the pager.
@@ -102,10 +163,13 @@ func (w *Pager) GetScrollYPosition() float64 {
 // ClearText clears current content on the pager.
 func (w *Pager) ClearText() {
 	w.text.ClearText()
-
-	width, height := w.text.Size()
-	w.viewport.SetContentSize(width, height, true)
-	w.viewport.ValidateView()
+	w.selecting = false
+	w.marks = nil
+	w.bookmarks = nil
+	if w.gutterWidth != w.calcGutterWidth() {
+		w.Resize()
+	}
+	w.updateContentSize()
 }
 
 // SetKeyword sets the keyword to be highlighted in the pager
@@ -114,6 +178,108 @@ func (w *Pager) SetKeyword(keyword string) {
 	w.PostEventWidgetContent(w)
 }
 
+// SetFormatter sets the formatter of the lines in the pager
+func (w *Pager) SetFormatter(f LineFormatter) {
+	w.text.SetFormatter(f)
+	w.updateContentSize()
+	w.PostEventWidgetContent(w)
+}
+
+// SetMinLevel sets the minimum level of the lines to be shown.  All lines
+// are shown if the level is level.Unknown.
+func (w *Pager) SetMinLevel(l level.Level) {
+	w.text.SetMinLevel(l)
+	w.updateContentSize()
+	w.PostEventWidgetContent(w)
+}
+
+// MinLevel returns the minimum level of the lines to be shown.
+func (w *Pager) MinLevel() level.Level {
+	return w.text.MinLevel()
+}
+
+// SetWrap enables or disables wrapping long lines at the width of the view.
+// The line on the top of the view is kept on toggling.
+func (w *Pager) SetWrap(wrap bool) {
+	w.wrap = wrap
+	w.updateWrapWidth()
+	w.PostEventWidgetContent(w)
+}
+
+// Wrap returns true if long lines are wrapped
+func (w *Pager) Wrap() bool {
+	return w.wrap
+}
+
+// updateWrapWidth wraps lines again by the current width of the view, and
+// scrolls to the line which was on the top of the view.
+func (w *Pager) updateWrapWidth() {
+	var width int
+	if w.wrap {
+		width, _ = w.viewport.Size()
+	}
+	if width == w.text.WrapWidth() {
+		return
+	}
+
+	_, y, _, _ := w.viewport.GetVisible()
+	line, col := w.text.rowPos(y)
+	w.text.SetWrapWidth(width)
+	w.updateContentSize()
+	if line >= 0 {
+		w.scrollTo(w.text.rowAt(line, col))
+	}
+}
+
+func (w *Pager) scrollTo(y int) {
+	_, top, _, _ := w.viewport.GetVisible()
+	w.viewport.ScrollDown(y - top)
+}
+
+// CurrentLine returns the index of the line on the top row of the pager.
+// The index is the one of Line and LineCount, which differs from the row
+// when lines are wrapped or hidden.  It returns -1 if no lines are shown.
+func (w *Pager) CurrentLine() int {
+	_, y, _, _ := w.viewport.GetVisible()
+	line, _ := w.text.rowPos(y)
+	return line
+}
+
+// Line returns the original line at the index including hidden lines
+func (w *Pager) Line(index int) string {
+	return w.text.Line(index)
+}
+
+// LineNumber returns the number of the line at the index shown on the
+// gutter.  The number keeps counting the lines dropped by the max lines.
+func (w *Pager) LineNumber(index int) int {
+	return w.text.lineID(index) + 1
+}
+
+// LineCount returns the count of the lines in the pager including hidden
+// lines
+func (w *Pager) LineCount() int {
+	return w.text.LineCount()
+}
+
+// Lines returns a copy of the lines in the pager.  The lines hidden by the
+// minimum level are excluded if the visibleOnly is true.
+func (w *Pager) Lines(visibleOnly bool) []Line {
+	return w.text.snapshot(0, w.text.LineCount(), visibleOnly)
+}
+
+// SetStripANSI sets whether the colors by ANSI escape sequences are stripped
+// or rendered in the pager.
+func (w *Pager) SetStripANSI(strip bool) {
+	w.text.SetStripANSI(strip)
+	w.PostEventWidgetContent(w)
+}
+
+// StripANSI returns true if the colors by ANSI escape sequences are stripped.
+func (w *Pager) StripANSI() bool {
+	return w.text.StripANSI()
+}
+
 // Keyword returns the current keyword in the content
 func (w *Pager) Keyword() string {
 	return w.text.Keyword()
@@ -153,18 +319,30 @@ func (w *Pager) FindPrev() bool {
 	return true
 }
 
+func (w *Pager) updateContentSize() {
+	width, height := w.text.Size()
+	w.viewport.SetContentSize(width, height, true)
+	w.viewport.ValidateView()
+}
+
 // Draw draws the Pager
 func (w *Pager) Draw() {
 	if w.view == nil {
 		return
 	}
+	w.drawGutter()
 	w.text.Draw()
 }
 
 // Resize is called when our View changes sizes.
 func (w *Pager) Resize() {
+	if w.view == nil {
+		return
+	}
 	width, height := w.view.Size()
-	w.viewport.Resize(0, 0, width, height)
+	w.gutterWidth = w.calcGutterWidth()
+	w.viewport.Resize(w.gutterWidth, 0, width-w.gutterWidth, height)
+	w.updateWrapWidth()
 	w.viewport.ValidateView()
 }
 
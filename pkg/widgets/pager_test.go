@@ -0,0 +1,197 @@
+package widgets
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell"
+	"github.com/mattn/go-runewidth"
+	"github.com/ueokande/logbook/pkg/level"
+)
+
+func TestPagerCurrentLine(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(10, 1)
+
+	p := NewPager()
+	p.SetView(screen)
+	if l := p.CurrentLine(); l != -1 {
+		t.Errorf("CurrentLine of the empty pager should be -1: %d", l)
+	}
+
+	p.AppendLine("[ERROR] 0123456789")
+	p.AppendLine("[DEBUG] hidden")
+	p.AppendLine("[INFO] last")
+	p.SetWrap(true)
+	p.SetMinLevel(level.Info)
+
+	// rows are "[ERROR] 01", "23456789" and "[INFO] las"
+	for _, c := range []struct {
+		row   int
+		index int
+		text  string
+	}{
+		{0, 0, "[ERROR] 0123456789"},
+		{1, 0, "[ERROR] 0123456789"},
+		{2, 2, "[INFO] last"},
+	} {
+		p.ScrollToTop()
+		for i := 0; i < c.row; i++ {
+			p.ScrollDown()
+		}
+		index := p.CurrentLine()
+		if index != c.index || p.Line(index) != c.text {
+			t.Errorf("row %d: CurrentLine = %d (%q), want %d", c.row, index, p.Line(index), c.index)
+		}
+	}
+}
+
+// drawRows draws the pager and returns the rows on the screen
+func drawRows(screen tcell.SimulationScreen, p *Pager) []string {
+	p.Draw()
+	screen.Show()
+
+	cells, width, height := screen.GetContents()
+	rows := make([]string, height)
+	for y := range rows {
+		var b strings.Builder
+		for x := 0; x < width; x++ {
+			c := cells[y*width+x]
+			if len(c.Runes) == 0 {
+				b.WriteByte(' ')
+				continue
+			}
+			b.WriteRune(c.Runes[0])
+			// skip the cell covered by the wide rune
+			x += runewidth.RuneWidth(c.Runes[0]) - 1
+		}
+		rows[y] = strings.TrimRight(b.String(), " ")
+	}
+	return rows
+}
+
+func TestPagerWrapWideRunes(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(5, 4)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.SetWrap(true)
+	p.AppendLine("abcあいう")
+	p.AppendLine("abcdあ")
+
+	rows := drawRows(screen, p)
+	expected := []string{"abcあ", "いう", "abcd", "あ"}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("unexpected rows: %q, want %q", rows, expected)
+	}
+}
+
+func TestPagerWrapKeepsTopLine(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(5, 2)
+
+	p := NewPager()
+	p.SetView(screen)
+	for _, line := range []string{"0aaaabbbbcccc", "1aaaabbbbcccc", "2aaaabbbbcccc", "3aaaabbbbcccc"} {
+		p.AppendLine(line)
+	}
+
+	p.ScrollToLine(3)
+	p.SetWrap(true)
+	if l := p.CurrentLine(); l != 2 {
+		t.Errorf("top line after wrapping: %d, want 2", l)
+	}
+	if rows := drawRows(screen, p); rows[0] != "2aaaa" {
+		t.Errorf("unexpected top row after wrapping: %q", rows[0])
+	}
+
+	// the continuation row "bbbbc" of the line stays on the top
+	p.ScrollDown()
+	screen.SetSize(4, 2)
+	p.Resize()
+	if l := p.CurrentLine(); l != 2 {
+		t.Errorf("top line after resizing: %d, want 2", l)
+	}
+	if rows := drawRows(screen, p); rows[0] != "abbb" {
+		t.Errorf("unexpected top row after resizing: %q", rows[0])
+	}
+
+	p.SetWrap(false)
+	if l := p.CurrentLine(); l != 2 {
+		t.Errorf("top line after unwrapping: %d, want 2", l)
+	}
+}
+
+func TestPagerFindNextOnWrappedRow(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(5, 1)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.SetWrap(true)
+	p.AppendLine("first")
+	p.AppendLine("aaaaaaFOObb")
+	p.SetKeyword("FOO")
+
+	if !p.FindNext() {
+		t.Fatal("keyword not found")
+	}
+	if x, y := p.text.HighlightPos(0); x != 1 || y != 2 {
+		t.Errorf("unexpected highlight position: (%d, %d)", x, y)
+	}
+	if rows := drawRows(screen, p); rows[0] != "aFOOb" {
+		t.Errorf("unexpected row on the highlight: %q", rows[0])
+	}
+	for x := 0; x < 5; x++ {
+		_, _, style, _ := screen.GetContent(x, 0)
+		if highlighted := style == styleHighlightCurrent; highlighted != (x >= 1 && x <= 3) {
+			t.Errorf("unexpected style at %d: %v", x, style)
+		}
+	}
+}
+
+func TestPagerSearchANSI(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(20, 1)
+
+	p := NewPager()
+	p.SetView(screen)
+	p.AppendLine("\x1b[31moops\x1b[0m: disk \x1b[31mdisk\x1b[0m")
+	p.SetKeyword("disk")
+	if !p.FindNext() {
+		t.Fatal("keyword not found")
+	}
+
+	if rows := drawRows(screen, p); rows[0] != "oops: disk disk" {
+		t.Fatalf("unexpected row: %q", rows[0])
+	}
+	red := tcell.StyleDefault.Foreground(tcell.ColorMaroon)
+	for x := 0; x < 15; x++ {
+		var expected tcell.Style
+		switch {
+		case x < 4:
+			expected = red
+		case x >= 6 && x < 10:
+			expected = styleHighlightCurrent
+		case x >= 11:
+			expected = red.Reverse(true)
+		}
+		if _, _, style, _ := screen.GetContent(x, 0); style != expected {
+			t.Errorf("unexpected style at %d: %v, want %v", x, style, expected)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("ActivateHighlight out of range should panic")
+		}
+	}()
+	p.text.ActivateHighlight(p.text.HighlightCount())
+}
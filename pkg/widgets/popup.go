@@ -0,0 +1,121 @@
+package widgets
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/gdamore/tcell/views"
+	"github.com/mattn/go-runewidth"
+)
+
+var (
+	stylePopupBorder = tcell.StyleDefault.Foreground(tcell.ColorSilver)
+	stylePopupTitle  = tcell.StyleDefault.Foreground(tcell.ColorWhite).Bold(true)
+)
+
+// Popup is a Widget with a border and a title, which is drawn over the
+// parent view.  The content of the popup is shown in a scrollable pager.
+type Popup struct {
+	view  views.View
+	frame views.ViewPort
+	inner views.ViewPort
+	title string
+	pager *Pager
+
+	views.WidgetWatchers
+}
+
+// NewPopup returns a new Popup
+func NewPopup() *Popup {
+	w := &Popup{
+		pager: NewPager(),
+	}
+	w.inner.SetView(&w.frame)
+	w.pager.SetView(&w.inner)
+	return w
+}
+
+// SetTitle sets the title on the border of the popup
+func (w *Popup) SetTitle(title string) {
+	w.title = title
+	w.PostEventWidgetContent(w)
+}
+
+// SetLines sets the lines of the content
+func (w *Popup) SetLines(lines []string) {
+	w.pager.ClearText()
+	for _, line := range lines {
+		w.pager.AppendLine(line)
+	}
+	w.PostEventWidgetContent(w)
+}
+
+// Pager returns the pager in the popup
+func (w *Popup) Pager() *Pager {
+	return w.pager
+}
+
+// Draw draws the popup with the border
+func (w *Popup) Draw() {
+	if w.view == nil {
+		return
+	}
+	width, height := w.frame.Size()
+	if width < 2 || height < 2 {
+		return
+	}
+
+	w.frame.Fill(' ', tcell.StyleDefault)
+	for x := 1; x < width-1; x++ {
+		w.frame.SetContent(x, 0, tcell.RuneHLine, nil, stylePopupBorder)
+		w.frame.SetContent(x, height-1, tcell.RuneHLine, nil, stylePopupBorder)
+	}
+	for y := 1; y < height-1; y++ {
+		w.frame.SetContent(0, y, tcell.RuneVLine, nil, stylePopupBorder)
+		w.frame.SetContent(width-1, y, tcell.RuneVLine, nil, stylePopupBorder)
+	}
+	w.frame.SetContent(0, 0, tcell.RuneULCorner, nil, stylePopupBorder)
+	w.frame.SetContent(width-1, 0, tcell.RuneURCorner, nil, stylePopupBorder)
+	w.frame.SetContent(0, height-1, tcell.RuneLLCorner, nil, stylePopupBorder)
+	w.frame.SetContent(width-1, height-1, tcell.RuneLRCorner, nil, stylePopupBorder)
+
+	x := 2
+	for _, c := range " " + w.title + " " {
+		if x >= width-2 {
+			break
+		}
+		w.frame.SetContent(x, 0, c, nil, stylePopupTitle)
+		x += runewidth.RuneWidth(c)
+	}
+
+	w.pager.Draw()
+}
+
+// Resize is called when our View changes sizes.  The popup is placed at the
+// center of the parent view.
+func (w *Popup) Resize() {
+	if w.view == nil {
+		return
+	}
+	pw, ph := w.view.Size()
+	width, height := pw*4/5, ph*4/5
+	w.frame.Resize((pw-width)/2, (ph-height)/2, width, height)
+	w.inner.Resize(1, 1, width-2, height-2)
+	w.pager.Resize()
+	w.PostEventWidgetResize(w)
+}
+
+// HandleEvent handles events on tcell
+func (w *Popup) HandleEvent(ev tcell.Event) bool {
+	return false
+}
+
+// SetView sets the parent view of the popup
+func (w *Popup) SetView(view views.View) {
+	w.view = view
+	w.frame.SetView(view)
+	w.Resize()
+}
+
+// Size returns the width and height of the popup
+func (w *Popup) Size() (int, int) {
+	return w.frame.Size()
+}
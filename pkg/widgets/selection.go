@@ -0,0 +1,67 @@
+package widgets
+
+// StartSelection starts selecting lines from the line on the top of the
+// view.  It returns false if the pager has no lines.
+func (w *Pager) StartSelection() bool {
+	_, y, _, _ := w.viewport.GetVisible()
+	line, _ := w.text.rowPos(y)
+	if line < 0 {
+		return false
+	}
+	w.selecting = true
+	w.selAnchor, w.selCursor = line, line
+	w.text.setSelection(line, line)
+	return true
+}
+
+// MoveSelection moves the end of the selection by delta lines shown in the
+// pager, and scrolls to the moved line.  The selection moves backward if the
+// delta is negative.
+func (w *Pager) MoveSelection(delta int) {
+	if !w.selecting {
+		return
+	}
+	w.selCursor = w.text.nextLine(w.selCursor, delta)
+	w.text.setSelection(w.selAnchor, w.selCursor)
+
+	x, _, _, _ := w.viewport.GetVisible()
+	w.viewport.MakeVisible(x, w.text.rowAt(w.selCursor, 0))
+	w.PostEventWidgetContent(w)
+}
+
+// ClearSelection clears the selection
+func (w *Pager) ClearSelection() {
+	w.selecting = false
+	w.text.clearSelection()
+}
+
+// Selecting returns true if lines are being selected
+func (w *Pager) Selecting() bool {
+	return w.selecting
+}
+
+// SelectedLines returns a copy of the selected lines shown in the pager
+func (w *Pager) SelectedLines() []Line {
+	if !w.selecting {
+		return nil
+	}
+	start, end := w.selAnchor, w.selCursor
+	if start > end {
+		start, end = end, start
+	}
+	return w.text.snapshot(start, end+1, true)
+}
+
+// SelectLine selects only the line at the index, and scrolls to the line
+func (w *Pager) SelectLine(index int) {
+	if index < 0 || index >= w.text.LineCount() {
+		return
+	}
+	w.selecting = true
+	w.selAnchor, w.selCursor = index, index
+	w.text.setSelection(index, index)
+
+	x, _, _, _ := w.viewport.GetVisible()
+	w.viewport.MakeVisible(x, w.text.rowAt(index, 0))
+	w.PostEventWidgetContent(w)
+}
@@ -0,0 +1,22 @@
+package widgets
+
+import "github.com/gdamore/tcell"
+
+// Styles returns the styles of the widgets by their names, such as "gutter"
+// or "tab.active".  The styles are changed by setting the values.
+func Styles() map[string]*tcell.Style {
+	return map[string]*tcell.Style{
+		"tab.active":     &styleTabActive,
+		"tab.inactive":   &styleTabInactive,
+		"tab.background": &styleTabBackground,
+		"gutter":         &styleGutter,
+		"gutter.mark":    &styleGutterMark,
+		"popup.border":   &stylePopupBorder,
+		"popup.title":    &stylePopupTitle,
+		"highlight":      &styleHighlightCurrent,
+		"selection":      &styleSelection,
+		"line.error":     &styleLineError,
+		"line.warn":      &styleLineWarn,
+		"line.debug":     &styleLineDebug,
+	}
+}
@@ -64,9 +64,9 @@ func (w *Tabs) SelectNext() {
 
 // SelectPrev selects previous tab of the current
 func (w *Tabs) SelectPrev() {
-	index := w.selected + 1
-	if index >= len(w.items) {
-		index = 0
+	index := w.selected - 1
+	if index < 0 {
+		index = len(w.items) - 1
 	}
 	w.SelectAt(index)
 }
@@ -0,0 +1,46 @@
+package widgets
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell"
+)
+
+// selectionRecorder records names of items selected in the widget
+type selectionRecorder struct {
+	names []string
+}
+
+func (r *selectionRecorder) HandleEvent(ev tcell.Event) bool {
+	if ev, ok := ev.(*EventItemSelected); ok {
+		r.names = append(r.names, ev.Name)
+		return true
+	}
+	return false
+}
+
+func TestTabsSelect(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	screen.Init()
+	screen.SetSize(40, 1)
+
+	w := NewTabs()
+	w.SetView(screen)
+	r := &selectionRecorder{}
+	w.Watch(r)
+	for _, name := range []string{"init", "app", "sidecar"} {
+		w.AddTab(name)
+	}
+
+	w.SelectAt(0)
+	w.SelectNext()
+	w.SelectPrev()
+	w.SelectPrev()
+	w.SelectNext()
+
+	expected := []string{"init", "app", "init", "sidecar", "init"}
+	if !reflect.DeepEqual(r.names, expected) {
+		t.Errorf("expected %v, actual %v", expected, r.names)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ueokande/logbook/pkg/ui"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+// writeLines writes the lines to w with the timestamps and the prefix by the
+// opts.  It returns the number of bytes written.
+func writeLines(w io.Writer, lines []widgets.Line, opts ui.SaveOptions, prefix string) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	if !opts.Prefix {
+		prefix = ""
+	}
+	for _, line := range lines {
+		m, err := bw.WriteString(formatLine(line.Text, line.Time, opts.Timestamps, prefix))
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// formatLine returns the line terminated by a newline, prefixed by the
+// timestamp if timestamps is true and the time is not zero, and the prefix.
+func formatLine(text string, t time.Time, timestamps bool, prefix string) string {
+	var s string
+	if timestamps && !t.IsZero() {
+		s += t.Format(time.RFC3339Nano) + " "
+	}
+	return s + prefix + text + "\n"
+}
+
+// containerPrefix returns the prefix of the lines of the container
+func containerPrefix(pod, container string) string {
+	return fmt.Sprintf("[%s/%s] ", pod, container)
+}
+
+// saveLines writes the lines to the file at path.  The file is truncated if
+// it already exists.
+func saveLines(path string, lines []widgets.Line, opts ui.SaveOptions, prefix string) (int64, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to create file")
+	}
+	n, err := writeLines(f, lines, opts, prefix)
+	if err != nil {
+		f.Close()
+		return n, errors.Wrap(err, "failed to write file")
+	}
+	return n, errors.Wrap(f.Close(), "failed to close file")
+}
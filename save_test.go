@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/ui"
+	"github.com/ueokande/logbook/pkg/widgets"
+)
+
+func TestWriteLines(t *testing.T) {
+	lines := []widgets.Line{
+		{Text: "hello", Time: time.Date(2019, 7, 1, 12, 0, 0, 0, time.UTC)},
+		{Text: "world"},
+	}
+
+	cases := []struct {
+		opts     ui.SaveOptions
+		expected string
+	}{
+		{ui.SaveOptions{}, "hello\nworld\n"},
+		{ui.SaveOptions{Timestamps: true}, "2019-07-01T12:00:00Z hello\nworld\n"},
+		{ui.SaveOptions{Prefix: true}, "[nginx/app] hello\n[nginx/app] world\n"},
+		{ui.SaveOptions{Timestamps: true, Prefix: true}, "2019-07-01T12:00:00Z [nginx/app] hello\n[nginx/app] world\n"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		n, err := writeLines(&buf, lines, c.opts, "[nginx/app] ")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != c.expected {
+			t.Errorf("%+v: %q != %q", c.opts, buf.String(), c.expected)
+		}
+		if n != int64(buf.Len()) {
+			t.Errorf("%+v: %d != %d", c.opts, n, buf.Len())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"github.com/gdamore/tcell"
+)
+
+// suspendableScreen is a tcell.Screen which can be finalized and initialized
+// again to hand over the terminal to other processes.  tcell.Screen discards
+// its event queue on Init, so that events posted while suspending are lost.
+// The screen keeps its own event queue across suspending.
+type suspendableScreen struct {
+	evch chan tcell.Event
+
+	// fini is closed on Fini to stop receiving events, and polled is closed
+	// when the receiving goroutine exits
+	fini   chan struct{}
+	polled chan struct{}
+
+	tcell.Screen
+}
+
+func newSuspendableScreen(s tcell.Screen) *suspendableScreen {
+	return &suspendableScreen{
+		evch:   make(chan tcell.Event, 10),
+		Screen: s,
+	}
+}
+
+// Init initializes the screen and starts receiving events of the screen
+func (s *suspendableScreen) Init() error {
+	if err := s.Screen.Init(); err != nil {
+		return err
+	}
+	fini, polled := make(chan struct{}), make(chan struct{})
+	s.fini, s.polled = fini, polled
+	go func() {
+		defer close(polled)
+		for {
+			// PollEvent returns nil after Fini
+			ev := s.Screen.PollEvent()
+			if ev == nil {
+				return
+			}
+			select {
+			case s.evch <- ev:
+			case <-fini:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Fini finalizes the screen and waits for the goroutine receiving events to
+// exit, because the screen replaces its event queue on Init again.
+func (s *suspendableScreen) Fini() {
+	s.Screen.Fini()
+	if s.fini != nil {
+		close(s.fini)
+		<-s.polled
+		s.fini, s.polled = nil, nil
+	}
+}
+
+// PollEvent waits for events posted or received from the screen
+func (s *suspendableScreen) PollEvent() tcell.Event {
+	return <-s.evch
+}
+
+// PostEvent posts the event.  It returns tcell.ErrEventQFull if the queue
+// is full.
+func (s *suspendableScreen) PostEvent(ev tcell.Event) error {
+	select {
+	case s.evch <- ev:
+		return nil
+	default:
+		return tcell.ErrEventQFull
+	}
+}
+
+// PostEventWait posts the event and waits until the queue has room
+func (s *suspendableScreen) PostEventWait(ev tcell.Event) {
+	s.evch <- ev
+}
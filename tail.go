@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/ueokande/logbook/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// exitCodePodFailed is the exit code of tail when some of the pods failed
+const exitCodePodFailed = 2
+
+// tailOptions is options to stream logs without UI
+type tailOptions struct {
+	namespace  string
+	selector   string
+	pod        *regexp.Regexp
+	container  *regexp.Regexp
+	prefix     bool
+	timestamps bool
+	since      time.Duration
+}
+
+// tailParams contains values of the command-line parameter of tail
+type tailParams struct {
+	container  string
+	prefix     bool
+	timestamps bool
+	since      time.Duration
+}
+
+func newTailCommand(p *params) *cobra.Command {
+	tp := tailParams{prefix: true}
+
+	cmd := &cobra.Command{}
+	cmd.Use = "tail [POD_REGEXP]"
+	cmd.Short = "Stream logs of pods and containers to stdout without UI"
+	cmd.Long = `Stream logs of pods and containers to stdout without UI.  New pods are
+followed until all pods have terminated or been deleted.  It exits with 0 if
+all pods have succeeded, or 2 if some of the pods failed.`
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	cmd.Flags().StringVarP(&tp.container, "container", "c", tp.container, "Regular expression of the container names")
+	cmd.Flags().BoolVarP(&tp.prefix, "prefix", "", tp.prefix, "Prefix each line with the pod and the container name")
+	cmd.Flags().BoolVarP(&tp.timestamps, "timestamps", "", tp.timestamps, "Prefix each line with the timestamp")
+	cmd.Flags().DurationVarP(&tp.since, "since", "", tp.since, "Show logs newer than the relative duration, such as 5m")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		client, config, err := p.loadConfig()
+		if err != nil {
+			return err
+		}
+		opts := tailOptions{
+			namespace:  config.Namespace,
+			selector:   config.Selector,
+			prefix:     tp.prefix,
+			timestamps: tp.timestamps,
+			since:      tp.since,
+		}
+		if len(args) > 0 {
+			if opts.pod, err = regexp.Compile(args[0]); err != nil {
+				return errors.Wrap(err, "invalid pod regexp")
+			}
+		}
+		if len(tp.container) > 0 {
+			if opts.container, err = regexp.Compile(tp.container); err != nil {
+				return errors.Wrap(err, "invalid container regexp")
+			}
+		}
+		return tail(context.Background(), client, cmd.OutOrStdout(), opts)
+	}
+	return cmd
+}
+
+// tail streams logs of the containers selected by opts to w.  It follows new
+// pods, and returns when all pods have terminated.  It returns an exitError if
+// some of the pods failed.
+func tail(ctx context.Context, client *k8s.Client, w io.Writer, opts tailOptions) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var since time.Time
+	if opts.since > 0 {
+		since = time.Now().Add(-opts.since)
+	}
+
+	var mu sync.Mutex
+	streams := client.NewLogStreams(ctx, since, func(ref k8s.ContainerRef, line k8s.LogLine) {
+		var prefix string
+		if opts.prefix {
+			prefix = containerPrefix(ref.Pod, ref.Container)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		io.WriteString(w, formatLine(line.Text, line.Time, opts.timestamps, prefix))
+	})
+	defer streams.Close()
+
+	var filter func(string) bool
+	if opts.container != nil {
+		filter = opts.container.MatchString
+	}
+
+	// phases of the pods to be tailed, and the last phases of the deleted
+	// pods, which are treated as terminated
+	phases := make(map[string]corev1.PodPhase)
+	deleted := make(map[string]corev1.PodPhase)
+	update := func(pod *corev1.Pod) {
+		if opts.pod != nil && !opts.pod.MatchString(pod.Name) {
+			return
+		}
+		delete(deleted, pod.Name)
+		phases[pod.Name] = pod.Status.Phase
+		streams.Watch(pod, filter)
+	}
+	remove := func(pod *corev1.Pod) {
+		if _, ok := phases[pod.Name]; !ok {
+			return
+		}
+		delete(phases, pod.Name)
+		deleted[pod.Name] = pod.Status.Phase
+	}
+
+	podOpts := k8s.PodOptions{LabelSelector: opts.selector}
+	list, err := client.ListPods(opts.namespace, podOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to list pods")
+	}
+	for i := range list.Items {
+		update(&list.Items[i])
+	}
+
+	podOpts.ResourceVersion = list.ResourceVersion
+	events, err := client.WatchPods(ctx, opts.namespace, podOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to watch pods")
+	}
+	for !allTerminated(phases, deleted) {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return errors.New("watching pods is closed")
+			}
+			switch ev.Type {
+			case k8s.PodAdded, k8s.PodModified:
+				update(ev.Pod)
+			case k8s.PodDeleted:
+				remove(ev.Pod)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
+	// wait for the rest of logs of the terminated pods
+	streams.Wait()
+
+	var failed []string
+	for _, m := range []map[string]corev1.PodPhase{phases, deleted} {
+		for name, phase := range m {
+			if phase == corev1.PodFailed {
+				failed = append(failed, name)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return &exitError{
+			code: exitCodePodFailed,
+			err:  fmt.Errorf("pod failed: %s", strings.Join(failed, ", ")),
+		}
+	}
+	return nil
+}
+
+// allTerminated returns true if there are pods and all of them have
+// succeeded, failed or been deleted
+func allTerminated(phases, deleted map[string]corev1.PodPhase) bool {
+	if len(phases)+len(deleted) == 0 {
+		return false
+	}
+	for _, phase := range phases {
+		if phase != corev1.PodSucceeded && phase != corev1.PodFailed {
+			return false
+		}
+	}
+	return true
+}
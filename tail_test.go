@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ueokande/logbook/pkg/k8s"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// tailTest runs tail with the fake clientset
+type tailTest struct {
+	t         *testing.T
+	clientset *fake.Clientset
+	done      chan error
+}
+
+// startTail starts tail after the pods are created, and waits for watching
+// the pods
+func startTail(t *testing.T, pods ...*corev1.Pod) *tailTest {
+	clientset := fake.NewSimpleClientset()
+	test := &tailTest{t: t, clientset: clientset, done: make(chan error, 1)}
+	for _, pod := range pods {
+		test.updatePod(pod)
+	}
+	go func() {
+		client := k8s.NewClientForClientset(clientset)
+		test.done <- tail(context.Background(), client, ioutil.Discard, tailOptions{namespace: "default"})
+	}()
+
+	// events of pods created before watching are not sent by the fake
+	deadline := time.Now().Add(5 * time.Second)
+	for !test.watched() {
+		if time.Now().After(deadline) {
+			t.Fatal("pods are not watched")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return test
+}
+
+func (test *tailTest) watched() bool {
+	for _, action := range test.clientset.Actions() {
+		if action.GetVerb() == "watch" {
+			return true
+		}
+	}
+	return false
+}
+
+// updatePod creates the pod, or updates it if exists
+func (test *tailTest) updatePod(pod *corev1.Pod) {
+	pods := test.clientset.CoreV1().Pods("default")
+	if _, err := pods.Update(pod); err == nil {
+		return
+	}
+	if _, err := pods.Create(pod); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+func (test *tailTest) deletePod(name string) {
+	if err := test.clientset.CoreV1().Pods("default").Delete(name, &metav1.DeleteOptions{}); err != nil {
+		test.t.Fatal(err)
+	}
+}
+
+// wait returns the error of tail
+func (test *tailTest) wait() error {
+	test.t.Helper()
+	select {
+	case err := <-test.done:
+		return err
+	case <-time.After(5 * time.Second):
+		test.t.Fatal("tail does not exit")
+		return nil
+	}
+}
+
+// phasePod returns a pod in the phase without containers
+func phasePod(name string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestTailSucceeded(t *testing.T) {
+	test := startTail(t, phasePod("job-a", corev1.PodRunning), phasePod("job-b", corev1.PodRunning))
+	test.updatePod(phasePod("job-a", corev1.PodSucceeded))
+	test.updatePod(phasePod("job-b", corev1.PodSucceeded))
+	if err := test.wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTailFailed(t *testing.T) {
+	test := startTail(t, phasePod("job-a", corev1.PodRunning), phasePod("job-b", corev1.PodRunning))
+	test.updatePod(phasePod("job-a", corev1.PodFailed))
+	test.updatePod(phasePod("job-b", corev1.PodSucceeded))
+
+	err := test.wait()
+	exit, ok := err.(*exitError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exit.code != exitCodePodFailed || exit.Error() != "pod failed: job-a" {
+		t.Errorf("unexpected exit error: %d %v", exit.code, exit)
+	}
+}
+
+func TestTailAllPodsDeleted(t *testing.T) {
+	test := startTail(t, phasePod("job-a", corev1.PodRunning))
+	test.deletePod("job-a")
+	if err := test.wait(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestTailDeletedPodFailed(t *testing.T) {
+	test := startTail(t, phasePod("job-a", corev1.PodRunning), phasePod("job-b", corev1.PodRunning))
+	test.updatePod(phasePod("job-a", corev1.PodFailed))
+	test.deletePod("job-a")
+	test.updatePod(phasePod("job-b", corev1.PodSucceeded))
+
+	err := test.wait()
+	exit, ok := err.(*exitError)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if exit.code != exitCodePodFailed || exit.Error() != "pod failed: job-a" {
+		t.Errorf("unexpected exit error: %d %v", exit.code, exit)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ueokande/logbook/pkg/source"
+)
+
+func newViewCommand(p *params) *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Use = "view FILE..."
+	cmd.Short = "View local log files in the pager"
+	cmd.Long = `View local log files in the pager.  Each file is shown in the list, and
+followed like "tail -F" even if it is rotated or truncated.  FILE "-" reads
+logs from stdin.`
+	cmd.Args = cobra.MinimumNArgs(1)
+	p.addUIFlags(cmd.Flags())
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		config := p.appConfig()
+		config.Cluster = "local"
+		config.Namespace = "files"
+
+		src := source.NewFiles(args, os.Stdin)
+		return NewApp(src, config).Run(context.Background())
+	}
+	return cmd
+}